@@ -0,0 +1,170 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"aethersim/sim"
+)
+
+// runBalance tunes the chosen template and ability fields toward a 50% win
+// rate for every character and prints the suggested changes.
+func runBalance(args []string) int {
+	var o options
+	fs := flag.NewFlagSet("balance", flag.ExitOnError)
+	o.addCommon(fs)
+	o.addBatch(fs)
+	var t tuning
+	t.add(fs, "character.*.base_strength,character.*.base_speed,ability.*.power")
+	iterations := fs.Int("iterations", 5, "passes over the parameters")
+	target := fs.Float64("target", 0.5, "win rate every character is pushed toward")
+	out := fs.String("out", "", "write the tuned data pack to this directory")
+	fs.Parse(args)
+	if err := t.setup(&o); err != nil {
+		return fail(err)
+	}
+	fmt.Fprintf(os.Stderr, "Tuning %d parameters over %d levels, %d battles per evaluation…\n",
+		len(t.params), len(t.levels), len(t.levels)*len(t.batch.Matchups)*o.trials)
+
+	res, err := sim.Balance(t.pack, sim.BalanceConfig{
+		Batch:      t.batch,
+		Levels:     t.levels,
+		Params:     t.params,
+		Iterations: *iterations,
+		Target:     *target,
+		Progress: func(it int, p sim.Param, loss float64) {
+			fmt.Fprintf(os.Stderr, "\rpass %d/%d  %-48s  rms off target %5.2f%%",
+				it+1, *iterations, p.Name, 100*math.Sqrt(loss))
+		},
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return fail(err)
+	}
+	if *out != "" {
+		if err := sim.SaveDataPack(*out, res.Pack); err != nil {
+			return fail(err)
+		}
+	}
+
+	switch o.format {
+	case "json":
+		err = writeJSON(res)
+	case "csv", "markdown":
+		var rows [][]string
+		for _, c := range res.Changes {
+			rows = append(rows, []string{c.Param.Name, sim.FormatFloat(c.Old), sim.FormatFloat(c.New)})
+		}
+		err = sim.WriteTable(os.Stdout, o.format, []string{"param", "old", "new"}, rows)
+	default:
+		printBalance(res)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+// printBalance prints the suggested changes as a diff and every character's
+// win rate before and after them.
+func printBalance(res *sim.BalanceResult) {
+	fmt.Printf("\n%d evaluations; rms distance from target %.2f%% → %.2f%%\n\n",
+		res.Evaluations, 100*math.Sqrt(res.LossBefore), 100*math.Sqrt(res.LossAfter))
+	if len(res.Changes) == 0 {
+		fmt.Println("No changes suggested.")
+	}
+	for _, c := range res.Changes {
+		fmt.Printf("- %s: %g\n+ %s: %g\n", c.Param.Name, c.Old, c.Param.Name, c.New)
+	}
+
+	fmt.Println("\nCharacter              | Before   | After")
+	fmt.Println("-----------------------+----------+---------")
+	for _, id := range sim.AllCharacterKeys() {
+		before, ok := res.Before[id]
+		if !ok {
+			continue
+		}
+		fmt.Printf("%-22s | %6.2f%%  | %6.2f%%\n", id, 100*before, 100*res.After[id])
+	}
+}
+
+// tuning holds the flags and setup shared by commands that play a sample of
+// matchups against variants of the data.
+type tuning struct {
+	paramGlobs string
+	levelList  string
+	teamSize   int
+	matchups   int
+
+	pack   *sim.DataPack
+	params []sim.Param
+	levels []int
+	batch  sim.BatchConfig
+}
+
+func (t *tuning) add(fs *flag.FlagSet, params string) {
+	fs.StringVar(&t.paramGlobs, "params", params,
+		"comma-separated globs over fields, e.g. character.mycera.evasion or ability.*.mana_cost")
+	fs.StringVar(&t.levelList, "levels", "", "levels to play at, e.g. 6, 4-8 or 3,6,9; -level if empty")
+	fs.IntVar(&t.teamSize, "team-size", TEAM_SIZE, "characters per team")
+	fs.IntVar(&t.matchups, "matchups", 2000, "random matchups played per level for each evaluation")
+}
+
+// setup runs o.setup, then selects the parameters and samples the matchups
+// every evaluation plays.
+func (t *tuning) setup(o *options) error {
+	if err := o.setup(); err != nil {
+		return err
+	}
+	roster := sim.AllCharacterKeys()
+	if err := checkTeamSize(t.teamSize, roster); err != nil {
+		return err
+	}
+	var err error
+	if t.levels, err = parseLevels(t.levelList, o.level); err != nil {
+		return fmt.Errorf("-levels: %w", err)
+	}
+	t.pack = sim.CurrentPack().Clone()
+	if t.params, err = sim.SelectParams(t.pack, strings.Split(t.paramGlobs, ",")); err != nil {
+		return err
+	}
+	t.batch = o.batchConfig(nil)
+	t.batch.Progress = nil // one bar per evaluation would scroll by too fast
+	t.batch.TargetWidth = 0
+	teams := sim.UniqueTeams(roster, t.teamSize)
+	t.batch.Matchups = sim.SampleMatchups(teams, t.matchups, o.seed)
+	return nil
+}
+
+// parseLevels reads a level list such as "6", "4-8" or "3,6,9", or returns
+// def alone if s is empty.
+func parseLevels(s string, def int) ([]int, error) {
+	if s == "" {
+		return []int{def}, nil
+	}
+	var out []int
+	for _, part := range strings.Split(s, ",") {
+		lo, hi, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		from, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, err
+		}
+		to := from
+		if isRange {
+			if to, err = strconv.Atoi(hi); err != nil {
+				return nil, err
+			}
+		}
+		if to < from {
+			return nil, fmt.Errorf("empty range %q", part)
+		}
+		for lv := from; lv <= to; lv++ {
+			out = append(out, lv)
+		}
+	}
+	return out, nil
+}
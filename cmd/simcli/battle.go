@@ -0,0 +1,155 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"aethersim/sim"
+)
+
+// runBattle plays one battle of team -a against team -b and prints it event
+// by event.
+func runBattle(args []string) int {
+	var o options
+	fs := flag.NewFlagSet("battle", flag.ExitOnError)
+	o.addCommon(fs)
+	a := fs.String("a", "", "ally team, comma-separated character keys")
+	b := fs.String("b", "", "enemy team, comma-separated character keys")
+	fs.StringVar(&o.format, "format", "text", "output format (text, jsonl)")
+	record := fs.String("record", "", "write a replayable battle log to this file")
+	fs.Parse(args)
+	format := o.format
+	o.format = "" // battle has its own formats; don't let setup reject jsonl
+	if err := o.setup(); err != nil {
+		return fail(err)
+	}
+	aKeys, err := parseTeam(*a)
+	if err != nil {
+		return fail(fmt.Errorf("-a: %w", err))
+	}
+	bKeys, err := parseTeam(*b)
+	if err != nil {
+		return fail(fmt.Errorf("-b: %w", err))
+	}
+
+	var sink sim.EventSink
+	var jsonl *sim.JSONLWriter
+	switch format {
+	case "text":
+		sink = sim.EventSinkFunc(func(ev sim.Event) {
+			if line := describeEvent(ev); line != "" {
+				fmt.Println(line)
+			}
+		})
+	case "jsonl":
+		jsonl = sim.NewJSONLWriter(os.Stdout)
+		sink = jsonl
+	default:
+		return fail(fmt.Errorf("unknown format %q", format))
+	}
+
+	policy, _ := sim.NewTeamPolicies(o.allyPolicy, o.enemyPolicy, o.overrides)
+	log, _, err := sim.RecordBattle(aKeys, bKeys, o.level, o.enemyLevel, o.seed, o.atb, policy, sink)
+	if err != nil {
+		return fail(err)
+	}
+	if jsonl != nil && jsonl.Err() != nil {
+		return fail(jsonl.Err())
+	}
+
+	if *record != "" {
+		f, err := os.Create(*record)
+		if err != nil {
+			return fail(err)
+		}
+		if err := sim.WriteBattleLog(f, log); err != nil {
+			f.Close()
+			return fail(err)
+		}
+		if err := f.Close(); err != nil {
+			return fail(err)
+		}
+	}
+	return 0
+}
+
+// describeEvent renders one event as a line of the text battle log.
+func describeEvent(ev sim.Event) string {
+	actor := sideName(ev.Actor, ev.ActorIsAlly)
+	target := sideName(ev.Target, ev.TargetIsAlly)
+	switch ev.Type {
+	case sim.EventTurnStarted:
+		return fmt.Sprintf("R%-3d T%-4d %s", ev.Round, ev.Turn, actor)
+	case sim.EventTurnSkipped:
+		switch {
+		case ev.Stat == sim.SkipCharging:
+			return fmt.Sprintf("           still charging %s", ev.Ability)
+		case ev.Ability != "":
+			return fmt.Sprintf("           %s fizzles, its targets are down", ev.Ability)
+		case ev.Stat != "":
+			return fmt.Sprintf("           can't act (%s)", ev.Stat)
+		}
+		return "           no valid action"
+	case sim.EventChargeStarted:
+		return fmt.Sprintf("           starts charging %s (%d turns)", ev.Ability, ev.Rounds)
+	case sim.EventAbilityUsed:
+		return fmt.Sprintf("           uses %s", ev.Ability)
+	case sim.EventManaChanged:
+		return fmt.Sprintf("           mana %+g", ev.Amount)
+	case sim.EventMissed:
+		return fmt.Sprintf("           misses %s", target)
+	case sim.EventShieldAbsorbed:
+		return fmt.Sprintf("           %s's shield absorbs the hit", target)
+	case sim.EventDamage:
+		if ev.Crit {
+			return fmt.Sprintf("           critical hit! %s takes %g damage", target, ev.Amount)
+		}
+		return fmt.Sprintf("           %s takes %g damage", target, ev.Amount)
+	case sim.EventHeal:
+		return fmt.Sprintf("           %s heals %g", target, ev.Amount)
+	case sim.EventBuffApplied:
+		return fmt.Sprintf("           %s gains %s %+.1f for %d rounds", target, ev.Stat, ev.Amount, ev.Rounds)
+	case sim.EventDebuffApplied:
+		return fmt.Sprintf("           %s is afflicted with %s for %d rounds", target, ev.Stat, ev.Rounds)
+	case sim.EventDebuffRefreshed:
+		return fmt.Sprintf("           %s's %s is refreshed for %d rounds", target, ev.Stat, ev.Rounds)
+	case sim.EventElementUnchanged:
+		return fmt.Sprintf("           %s is already %s", target, ev.Element)
+	case sim.EventDebuffResisted:
+		return fmt.Sprintf("           %s resists %s", target, ev.Stat)
+	case sim.EventElementGained:
+		return fmt.Sprintf("           %s becomes %s", target, ev.Element)
+	case sim.EventElementLost:
+		return fmt.Sprintf("           %s is no longer %s", target, ev.Element)
+	case sim.EventEffectExpired:
+		return fmt.Sprintf("           %s's %s wears off", target, ev.Stat)
+	case sim.EventDoTTick:
+		return fmt.Sprintf("           %s takes %g %s damage", target, ev.Amount, ev.Stat)
+	case sim.EventCharacterDown:
+		return fmt.Sprintf("           %s is down!", target)
+	case sim.EventRoundEnded:
+		return fmt.Sprintf("---- end of round %d", ev.Round)
+	case sim.EventBattleEnded:
+		result := "enemies win"
+		if ev.PlayerWon {
+			result = "allies win"
+		}
+		if ev.TimedOut {
+			result += fmt.Sprintf(" (round limit of %d reached)", sim.MAX_ROUNDS)
+		}
+		return result
+	}
+	return ""
+}
+
+// sideName tags a character key with its side, since both teams may share keys.
+func sideName(id string, isAlly bool) string {
+	if id == "" {
+		return ""
+	}
+	if isAlly {
+		return id + " (A)"
+	}
+	return id + " (B)"
+}
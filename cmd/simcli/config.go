@@ -1,7 +1,17 @@
 package main
 
+// Defaults for the command-line flags of the same names.
 const LEVEL = 6
 const TRIALS = 3
 const TEAM_SIZE = 3
 
+// SEED is the base seed; every battle of a run derives its own from it.
+const SEED = 1
+
+// BATCH_SIZE is how many battles pass between progress bar redraws.
 const BATCH_SIZE = 10000
+
+// REASONABLE_WIN_RATE and TRIVIAL_WIN_RATE bound the best counter's win rate
+// for a story fight to count as fair.
+const REASONABLE_WIN_RATE = 0.5
+const TRIVIAL_WIN_RATE = 0.95
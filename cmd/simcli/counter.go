@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"aethersim/sim"
+)
+
+// runCounter searches the roster for the ally teams that beat the -enemy team
+// most often, pruning weak candidates between rounds.
+func runCounter(args []string) int {
+	var o options
+	fs := flag.NewFlagSet("counter", flag.ExitOnError)
+	o.addCommon(fs)
+	o.addBatch(fs)
+	enemy := fs.String("enemy", "", "enemy team, comma-separated character keys")
+	keep := fs.Int("keep", 10, "number of finalist teams")
+	reasonable := fs.Float64("reasonable", REASONABLE_WIN_RATE, "win rate the best counter should reach for the fight to have a reasonable answer")
+	trivial := fs.Float64("trivial", TRIVIAL_WIN_RATE, "win rate above which a counter makes the fight trivial")
+	fs.Parse(args)
+	if err := o.setup(); err != nil {
+		return fail(err)
+	}
+	if *keep <= 0 {
+		return fail(fmt.Errorf("-keep must be positive, got %d", *keep))
+	}
+	enemyKeys, err := parseTeam(*enemy)
+	if err != nil {
+		return fail(fmt.Errorf("-enemy: %w", err))
+	}
+
+	// -max-trials caps the battles any one candidate plays over all rounds
+	batch := o.batchConfig(nil)
+	fmt.Fprintf(os.Stderr, "Searching for counters to %v…\n", enemyKeys)
+	res, err := sim.FindCounters(sim.CounterConfig{Batch: batch, Enemy: enemyKeys, Keep: *keep})
+	if err != nil {
+		return fail(err)
+	}
+
+	switch o.format {
+	case "json":
+		err = writeJSON(res)
+	case "csv", "markdown":
+		var rows [][]string
+		for _, t := range res.Teams {
+			rows = append(rows, []string{sim.TeamKey(t.Team), strconv.Itoa(t.Rounds), strconv.Itoa(t.Battles),
+				strconv.Itoa(t.Wins), sim.FormatFloat(t.WinRate), sim.FormatFloat(t.WinRateCI.Lo), sim.FormatFloat(t.WinRateCI.Hi)})
+		}
+		err = sim.WriteTable(os.Stdout, o.format, []string{"team", "rounds", "battles", "wins", "win_rate", "win_rate_lo", "win_rate_hi"}, rows)
+	default:
+		printCounters(res, *keep, *reasonable, *trivial)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+// printCounters lists the finalists and says whether the fight has a
+// reasonable answer and whether some answer makes it trivial.
+func printCounters(res *sim.CounterResult, keep int, reasonable, trivial float64) {
+	fmt.Printf("\n%d battles over %d rounds against %s\n\n", res.Battles, res.Rounds, sim.TeamKey(res.Enemy))
+	if len(res.Teams) == 0 {
+		fmt.Println("No candidate teams to try.")
+		return
+	}
+	fmt.Println("Team                                           | Win%     | CI              | Battles")
+	fmt.Println("-----------------------------------------------+----------+-----------------+---------")
+	for _, t := range res.Teams[:min(keep, len(res.Teams))] {
+		fmt.Printf("%-46s | %6.2f%%  | %6.2f–%6.2f%% | %7d\n",
+			sim.TeamKey(t.Team), 100*t.WinRate, 100*t.WinRateCI.Lo, 100*t.WinRateCI.Hi, t.Battles)
+	}
+	fmt.Println()
+	best := res.Teams[0].WinRateCI
+	switch {
+	case best.Hi < reasonable:
+		fmt.Printf("No reasonable answer: even the best team is below %.0f%%.\n", 100*reasonable)
+	case best.Lo > trivial:
+		fmt.Printf("Trivial: the best team wins more than %.0f%% of the time.\n", 100*trivial)
+	default:
+		fmt.Println("Has a reasonable answer and no trivial one.")
+	}
+}
@@ -1,167 +1,237 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"math"
+	"os"
+	"runtime"
 	"strings"
 	"time"
 
 	"aethersim/sim"
 )
 
-type Stats struct {
-	Battles     int
-	Wins        int
-	DamageDealt float64
-	DamageTaken float64
-	HealingDone float64
-	DoTDealt    float64
+const usage = `usage: simcli <command> [flags]
+
+commands:
+  roundrobin  every team of -team-size against every other team (default)
+  matchup     many trials of one team against another
+  counter     search the roster for the teams that beat an enemy team
+  balance     tune template and ability fields toward 50% win rates
+  sensitivity rank template and ability fields by how much they move win rates
+  sweep       chart every character's win rate across a range of levels
+  battle      a single battle, printed turn by turn
+  replay      re-run a recorded battle and report where it diverges
+  validate    check a data pack for problems
+
+Run "simcli <command> -h" for the flags of a command.
+`
+
+var commands = map[string]func(args []string) int{
+	"roundrobin":  runRoundRobin,
+	"matchup":     runMatchup,
+	"counter":     runCounter,
+	"balance":     runBalance,
+	"sensitivity": runSensitivity,
+	"sweep":       runSweep,
+	"battle":      runBattle,
+	"replay":      runReplay,
+	"validate":    runValidate,
 }
 
 func main() {
-	completed := 0
-	start := time.Now()
-	totalRounds := 0
-	totalTurns := 0
-
-	testCalc := 30 * float64(27+5) / math.Pow(float64(17+13), 0.9) * (float64((6*2)/4+5) / 30)
-	fmt.Printf("Test Calculation: %10.3f\n", testCalc)
+	args := os.Args[1:]
+	name := "roundrobin"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		fmt.Print(usage)
+		return
+	}
+	run, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", name, usage)
+		os.Exit(2)
+	}
+	os.Exit(run(args))
+}
 
-	// 1) Gather your full roster of character‐keys:
-	roster := sim.AllCharacterKeys() // returns []string, e.g. ["chocolate_chip", "flitterfyre", …]
+// options holds the flags shared between commands.
+type options struct {
+	dataDir     string
+	level       int
+	enemyLevel  int
+	seed        int64
+	policy      string
+	allyPolicy  string
+	enemyPolicy string
+	override    string
+	overrides   map[sim.Slot]string
+	atb         bool
+	trials      int
+	workers     int
+	format      string
+	table       string
+	top         int
+	confidence  float64
+	ciWidth     float64
+	maxTrials   int
+}
 
-	// 2) Build all unique 3‐member teams (combinations without repetition)
-	teams := generateUniqueTeams(roster, TEAM_SIZE)
+// addCommon registers the flags every simulating command takes.
+func (o *options) addCommon(fs *flag.FlagSet) {
+	fs.StringVar(&o.dataDir, "data", "", "directory holding a data pack (characters, abilities, elements); built-in data if empty")
+	fs.IntVar(&o.level, "level", LEVEL, "character level")
+	fs.IntVar(&o.enemyLevel, "enemy-level", 0, "enemy team level; same as -level if 0")
+	fs.Int64Var(&o.seed, "seed", SEED, "base random seed")
+	fs.StringVar(&o.policy, "policy", "utility", "decision policy for both teams ("+strings.Join(sim.PolicyNames(), ", ")+")")
+	fs.StringVar(&o.allyPolicy, "ally-policy", "", "decision policy for the ally team; overrides -policy")
+	fs.StringVar(&o.enemyPolicy, "enemy-policy", "", "decision policy for the enemy team; overrides -policy")
+	fs.StringVar(&o.override, "override", "", "comma-separated per-character policies, e.g. ally:mycera=random,enemy:frostnip=greedy")
+	fs.BoolVar(&o.atb, "atb", false, "initiative timeline: faster characters may act more than once per round")
+}
 
-	matchupCount := len(teams) * (len(teams) + 1) / 2
-	totalBattles := matchupCount * TRIALS
-	fmt.Printf(
-		"Simulating %d unique team matchups × %d trials = %d total battles…\n\n",
-		matchupCount, TRIALS, totalBattles,
-	)
+// addBatch registers the flags of commands that run many battles.
+func (o *options) addBatch(fs *flag.FlagSet) {
+	fs.IntVar(&o.trials, "trials", TRIALS, "battles per matchup")
+	fs.IntVar(&o.workers, "workers", runtime.NumCPU(), "number of battles simulated in parallel")
+	fs.StringVar(&o.format, "format", "text", "output format (text, json, csv, markdown)")
+	fs.Float64Var(&o.confidence, "confidence", sim.DefaultConfidence, "confidence level of reported intervals")
+	fs.Float64Var(&o.ciWidth, "ci-width", 0, "adaptive mode: keep sampling each matchup until its win-rate interval is this narrow (e.g. 0.1)")
+	fs.IntVar(&o.maxTrials, "max-trials", 1000, "adaptive mode: most battles played per matchup")
+	fs.IntVar(&o.top, "top", 10, "best and worst teams and pairs listed in text output")
+	fs.StringVar(&o.table, "table", sim.TableCharacters, "table written by -format csv (characters, teams, matchups, matrix, shapley, synergy, abilities, ability_totals, distributions, histograms)")
+}
 
-	// 3) Prepare stats map: key → { battles, wins }
-	stats := make(map[string]*Stats, len(roster))
-	for _, id := range roster {
-		stats[id] = &Stats{}
-	}
-
-	// 4) Simulate each distinct pairing i ≤ j
-	for i := 0; i < len(teams); i++ {
-		aKeys := teams[i]
-
-		for j := i; j < len(teams); j++ {
-			bKeys := teams[j]
-
-			// simulate TRIALS battles for this matchup
-			for t := 0; t < TRIALS; t++ {
-				// rebuild brand‐new Character structs each time
-				aTeam := sim.MakeTeam(aKeys, LEVEL, true)
-				bTeam := sim.MakeTeam(bKeys, LEVEL, false)
-
-				engine := sim.NewEngine(aTeam, bTeam)
-
-				for !engine.GameOver {
-					engine.Step(sim.UtilityDecision)
-
-					// record per-target delta stats for this round
-					for _, imp := range engine.LastImpacts {
-						rec := stats[imp.ActorID]
-						if imp.Delta > 0 {
-							rec.DamageDealt += imp.Delta
-							stats[imp.TargetID].DamageTaken += imp.Delta
-							if imp.IsDebuff {
-								rec.DoTDealt += imp.Delta
-							}
-						} else if imp.Delta < 0 {
-							// negative delta means healing
-							rec.HealingDone += -imp.Delta
-							// healed HP is not “damage taken”
-						}
-					}
-				}
-
-				// tally results
-				alliesWin := engine.PlayerWon
-				// for each char in A-team
-				for _, c := range aTeam {
-					stats[c.ID].Battles++
-					if alliesWin {
-						stats[c.ID].Wins++
-					}
-				}
-				// for each char in B-team
-				for _, c := range bTeam {
-					stats[c.ID].Battles++
-					if !alliesWin {
-						stats[c.ID].Wins++
-					}
-				}
-
-				totalRounds += engine.TotalRounds
-				totalTurns += engine.TotalTurns
-
-				completed++
-				if completed%BATCH_SIZE == 0 {
-					drawProgress(completed, totalBattles, start)
-				}
-			}
+// setup resolves defaults, checks policy names and installs the data pack.
+func (o *options) setup() error {
+	if o.enemyLevel == 0 {
+		o.enemyLevel = o.level
+	}
+	if o.allyPolicy == "" {
+		o.allyPolicy = o.policy
+	}
+	if o.enemyPolicy == "" {
+		o.enemyPolicy = o.policy
+	}
+	var err error
+	if o.overrides, err = parseOverrides(o.override); err != nil {
+		return err
+	}
+	if _, err := sim.NewTeamPolicies(o.allyPolicy, o.enemyPolicy, o.overrides); err != nil {
+		return err
+	}
+	switch o.format {
+	case "", "text", "json", "csv", "markdown":
+	default:
+		return fmt.Errorf("unknown format %q", o.format)
+	}
+	if o.dataDir != "" {
+		pack, err := sim.LoadDataPack(o.dataDir)
+		if err != nil {
+			return err
 		}
+		pack.Install()
 	}
+	for slot := range o.overrides {
+		if _, ok := sim.CharacterTemplates[slot.ID]; !ok {
+			return fmt.Errorf("-override %s: unknown character key %q", slot, slot.ID)
+		}
+	}
+	return nil
+}
 
-	drawProgress(completed, totalBattles, start)
+// batchConfig builds a batch for matchups from the parsed flags, with a
+// progress bar on stderr.
+func (o *options) batchConfig(matchups []sim.Matchup) sim.BatchConfig {
+	start := time.Now()
+	lastDrawn := 0
+	return sim.BatchConfig{
+		Matchups:    matchups,
+		Trials:      o.trials,
+		AllyLevel:   o.level,
+		EnemyLevel:  o.enemyLevel,
+		Seed:        o.seed,
+		Workers:     o.workers,
+		AllyPolicy:  o.allyPolicy,
+		EnemyPolicy: o.enemyPolicy,
+		Overrides:   o.overrides,
+		ATB:         o.atb,
+		Confidence:  o.confidence,
+		TargetWidth: o.ciWidth,
+		MaxTrials:   o.maxTrials,
+		Progress: func(done, total int) {
+			if done/BATCH_SIZE != lastDrawn/BATCH_SIZE || done == total {
+				drawProgress(done, total, start)
+				lastDrawn = done
+			}
+		},
+	}
+}
 
-	// 5) Print out aggregate win‐rates plus per‐game averages
-	fmt.Print("\n\n")
-	fmt.Println("Character              | Win%     | Tot Dmg▶  | Tot Dmg◀  | Tot Heal  | Tot DoT ")
-	fmt.Println("-----------------------+----------+-----------+-----------+-----------+----------")
-	for _, k := range roster {
-		s := stats[k]
-		if s.Battles == 0 {
-			fmt.Printf("%-22s |    N/A   |    N/A    |    N/A    |   N/A    |   N/A\n", k)
+// parseOverrides reads the -override flag: comma-separated slot=policy pairs.
+func parseOverrides(s string) (map[sim.Slot]string, error) {
+	var overrides map[sim.Slot]string
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
 			continue
 		}
-
-		winPct := 100 * float64(s.Wins) / float64(s.Battles)
-		avgDealt := s.DamageDealt / float64(s.Battles)
-		avgTaken := s.DamageTaken / float64(s.Battles)
-		avgHeal := s.HealingDone / float64(s.Battles)
-		avgDoT := s.DoTDealt / float64(s.Battles)
-
-		fmt.Printf("%-22s | %6.2f%%  | %9.1f | %9.1f | %9.1f | %9.1f\n",
-			k,
-			winPct,
-			avgDealt,
-			avgTaken,
-			avgHeal,
-			avgDoT,
-		)
-	}
-	avgRoundsPerGame := float64(totalRounds) / float64(totalBattles)
-	avgTurnsPerGame := float64(totalTurns) / float64(totalBattles)
-	fmt.Print("\n")
-	fmt.Printf("Average Rounds per Game: %12.5f\n", avgRoundsPerGame)
-	fmt.Printf("Average Turns per Game: %12.5f\n", avgTurnsPerGame)
+		slot, policy, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("-override %q: want slot=policy", pair)
+		}
+		sl, err := sim.ParseSlot(slot)
+		if err != nil {
+			return nil, err
+		}
+		if overrides == nil {
+			overrides = map[sim.Slot]string{}
+		}
+		overrides[sl] = policy
+	}
+	return overrides, nil
 }
 
-// generateUniqueTeams returns all k‐sized combinations of keys, without repetition.
-func generateUniqueTeams(keys []string, k int) [][]string {
-	var res [][]string
-	var helper func(start int, combo []string)
-	helper = func(start int, combo []string) {
-		if len(combo) == k {
-			// copy
-			c := make([]string, k)
-			copy(c, combo)
-			res = append(res, c)
-			return
+// parseTeam splits a comma-separated list of character keys.
+func parseTeam(s string) ([]string, error) {
+	var keys []string
+	for _, k := range strings.Split(s, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
 		}
-		for i := start; i < len(keys); i++ {
-			helper(i+1, append(combo, keys[i]))
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("empty team")
+	}
+	for _, k := range keys {
+		if _, ok := sim.CharacterTemplates[k]; !ok {
+			return nil, fmt.Errorf("unknown character key %q", k)
 		}
 	}
-	helper(0, []string{})
-	return res
+	return keys, nil
+}
+
+// usageError is a mistake on the command line rather than a failed run.
+type usageError struct{ error }
+
+// checkTeamSize rejects a -team-size no team drawn from roster can have.
+func checkTeamSize(n int, roster []string) error {
+	if n < 1 || n > len(roster) {
+		return usageError{fmt.Errorf("-team-size must be between 1 and %d, got %d", len(roster), n)}
+	}
+	return nil
+}
+
+// fail prints err and returns the exit code for a failed command: 2 for a
+// usage error, as the flag package exits with, and 1 otherwise.
+func fail(err error) int {
+	fmt.Fprintln(os.Stderr, err)
+	if errors.As(err, new(usageError)) {
+		return 2
+	}
+	return 1
 }
 
 // drawProgress overwrites the current line with a bar, pct, counts, rate, elapsed & ETA.
@@ -183,7 +253,7 @@ func drawProgress(completed, total int, start time.Time) {
 	}
 	bar := strings.Repeat("█", filled) + strings.Repeat(" ", width-filled)
 
-	fmt.Printf(
+	fmt.Fprintf(os.Stderr,
 		"\r[%s] %6.2f%%  %6d/%6d (%5.1f sim/s) elapsed %s ETA %s",
 		bar,
 		pct*100,
@@ -194,7 +264,7 @@ func drawProgress(completed, total int, start time.Time) {
 		formatDuration(eta),
 	)
 	if completed == total {
-		fmt.Print("\n")
+		fmt.Fprint(os.Stderr, "\n")
 	}
 }
 
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+
+	"aethersim/sim"
+)
+
+// runMatchup plays -trials battles of team -a (allies) against team -b.
+func runMatchup(args []string) int {
+	var o options
+	fs := flag.NewFlagSet("matchup", flag.ExitOnError)
+	o.addCommon(fs)
+	o.addBatch(fs)
+	a := fs.String("a", "", "ally team, comma-separated character keys")
+	b := fs.String("b", "", "enemy team, comma-separated character keys")
+	fs.Parse(args)
+	if err := o.setup(); err != nil {
+		return fail(err)
+	}
+	aKeys, err := parseTeam(*a)
+	if err != nil {
+		return fail(fmt.Errorf("-a: %w", err))
+	}
+	bKeys, err := parseTeam(*b)
+	if err != nil {
+		return fail(fmt.Errorf("-b: %w", err))
+	}
+
+	res, err := sim.RunBatch(o.batchConfig([]sim.Matchup{{A: aKeys, B: bKeys}}))
+	if err != nil {
+		return fail(err)
+	}
+	if o.format == "text" {
+		m := res.Matchups[0]
+		ci := sim.WilsonInterval(m.AWins, m.Battles, res.Confidence)
+		fmt.Printf("\n%v vs %v: allies won %d of %d (%.2f%%, %g%% CI %.2f–%.2f%%)\n",
+			aKeys, bKeys, m.AWins, m.Battles, 100*float64(m.AWins)/float64(m.Battles),
+			100*res.Confidence, 100*ci.Lo, 100*ci.Hi)
+	}
+	roster := slices.Concat(aKeys, bKeys)
+	slices.Sort(roster)
+	return writeResult(&o, res, slices.Compact(roster))
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// writeJSON prints v to stdout as one indented JSON document.
+func writeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"aethersim/sim"
+)
+
+// runReplay re-runs a battle log written by "battle -record" under the current
+// rules and reports the first step that no longer matches.
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	dataDir := fs.String("data", "", "directory holding a data pack; built-in data if empty")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: simcli replay [-data dir] battle.json")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	if *dataDir != "" {
+		pack, err := sim.LoadDataPack(*dataDir)
+		if err != nil {
+			return fail(err)
+		}
+		pack.Install()
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return fail(err)
+	}
+	log, err := sim.ReadBattleLog(f)
+	f.Close()
+	if err != nil {
+		return fail(err)
+	}
+
+	if d := sim.Replay(log); d != nil {
+		fmt.Println("diverged at", d)
+		return 1
+	}
+	fmt.Printf("reproduced all %d steps (seed %d, %d rounds)\n", len(log.Steps), log.Seed, log.TotalRounds)
+	return 0
+}
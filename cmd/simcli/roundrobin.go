@@ -0,0 +1,238 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"aethersim/sim"
+)
+
+// runRoundRobin plays every team of -team-size against every other team.
+func runRoundRobin(args []string) int {
+	var o options
+	fs := flag.NewFlagSet("roundrobin", flag.ExitOnError)
+	o.addCommon(fs)
+	o.addBatch(fs)
+	teamSize := fs.Int("team-size", TEAM_SIZE, "characters per team")
+	fs.Parse(args)
+	if err := o.setup(); err != nil {
+		return fail(err)
+	}
+
+	// 1) Gather your full roster of character‐keys:
+	roster := sim.AllCharacterKeys() // returns []string, e.g. ["chocolate_chip", "flitterfyre", …]
+	if err := checkTeamSize(*teamSize, roster); err != nil {
+		return fail(err)
+	}
+
+	// 2) Build all unique teams (combinations without repetition)
+	teams := sim.UniqueTeams(roster, *teamSize)
+
+	// 3) Every distinct pairing i ≤ j
+	matchups := sim.RoundRobin(teams)
+	fmt.Fprintf(os.Stderr,
+		"Simulating %d unique team matchups × %d trials = %d total battles…\n\n",
+		len(matchups), o.trials, len(matchups)*o.trials,
+	)
+
+	// 4) Simulate them across all workers
+	res, err := sim.RunBatch(o.batchConfig(matchups))
+	if err != nil {
+		return fail(err)
+	}
+	return writeResult(&o, res, roster)
+}
+
+// writeResult prints a batch result in the format chosen by -format.
+func writeResult(o *options, res *sim.BatchResult, roster []string) int {
+	report := sim.NewReport(res)
+	var err error
+	switch o.format {
+	case "json":
+		err = sim.WriteReportJSON(os.Stdout, report)
+	case "csv":
+		err = sim.WriteReportCSV(os.Stdout, report, o.table)
+	case "markdown":
+		err = sim.WriteReportMarkdown(os.Stdout, report)
+	default:
+		printCharacterTable(report, roster)
+		printTeamStandings(report, o.top)
+		printContributions(report)
+		printSynergies(report, o.top)
+		printAbilityTable(report)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+// printCharacterTable prints aggregate win‐rates plus per‐game averages.
+func printCharacterTable(r *sim.Report, roster []string) {
+	rows := map[string]sim.CharacterRow{}
+	for _, c := range r.Characters {
+		rows[c.ID] = c
+	}
+
+	fmt.Print("\n")
+	fmt.Printf("Character              | Win%%     | %2.0f%% CI         | Rating      | Tot Dmg▶  | Tot Dmg◀  | Tot Heal  | Tot DoT \n", 100*r.Confidence)
+	fmt.Println("-----------------------+----------+-----------------+-------------+-----------+-----------+-----------+----------")
+	for _, k := range roster {
+		c, ok := rows[k]
+		if !ok || c.Battles == 0 {
+			fmt.Printf("%-22s |    N/A   |       N/A       |     N/A     |    N/A    |    N/A    |   N/A    |   N/A\n", k)
+			continue
+		}
+
+		fmt.Printf("%-22s | %6.2f%%  | %6.2f–%6.2f%% | %5.1f ±%4.1f | %9.1f | %9.1f | %9.1f | %9.1f\n",
+			k,
+			100*c.WinRate,
+			100*c.WinRateCI.Lo,
+			100*c.WinRateCI.Hi,
+			c.Rating.Mu,
+			c.Rating.Sigma,
+			c.DamageDealt,
+			c.DamageTaken,
+			c.HealingDone,
+			c.DoTDealt,
+		)
+	}
+	fmt.Print("\n")
+	fmt.Printf("Average Rounds per Game: %12.5f  (%.3f–%.3f)\n", r.AvgRoundsPerGame, r.AvgRoundsCI.Lo, r.AvgRoundsCI.Hi)
+	fmt.Printf("Average Turns per Game: %12.5f  (%.3f–%.3f)\n", r.AvgTurnsPerGame, r.AvgTurnsCI.Lo, r.AvgTurnsCI.Hi)
+	printDistributions(r)
+}
+
+// printDistributions prints percentiles of battle length, time to the first
+// knockout and the winner's health left, a histogram of battle length, and
+// how many battles ran into the round limit.
+func printDistributions(r *sim.Report) {
+	fmt.Println("\nDistribution           | Mean     | P10  | P25  | P50  | P75  | P90  | Max")
+	fmt.Println("-----------------------+----------+------+------+------+------+------+------")
+	for _, d := range r.Distributions {
+		fmt.Printf("%-22s | %8.2f | %4d | %4d | %4d | %4d | %4d | %4d\n",
+			d.Metric, d.Mean, d.P10, d.P25, d.P50, d.P75, d.P90, d.Max)
+	}
+
+	h := r.Histograms.Rounds
+	if h.N > 0 {
+		// at most 20 bars
+		width := (len(h.Counts) + 19) / 20
+		var buckets []int
+		for v, c := range h.Counts {
+			if v/width >= len(buckets) {
+				buckets = append(buckets, 0)
+			}
+			buckets[v/width] += c
+		}
+		most := slices.Max(buckets)
+		fmt.Println("\nRounds per battle")
+		for i, c := range buckets {
+			label := strconv.Itoa(i * width)
+			if width > 1 {
+				label += "–" + strconv.Itoa((i+1)*width-1)
+			}
+			fmt.Printf("%7s | %-40s %6.2f%%\n", label, strings.Repeat("█", (40*c+most-1)/most), 100*float64(c)/float64(h.N))
+		}
+	}
+	fmt.Printf("\nBattles stopped at the %d-round limit: %d (%.2f%%, %g%% CI %.2f–%.2f%%)\n",
+		sim.MAX_ROUNDS, r.TimedOut, 100*r.TimedOutRate, 100*r.Confidence, 100*r.TimedOutCI.Lo, 100*r.TimedOutCI.Hi)
+}
+
+// printTeamStandings lists the n best and n worst teams with their rating and
+// the opponent each one struggles against most.
+func printTeamStandings(r *sim.Report, n int) {
+	standings := r.Teams
+	if n <= 0 || len(standings) < 2 {
+		return
+	}
+	n = min(n, len(standings)/2)
+
+	list := func(title string, rows []sim.TeamRow) {
+		fmt.Printf("\n%s\n", title)
+		fmt.Println("Team                                           | Win%     | Rating      | Hardest counter                                | Win% vs")
+		fmt.Println("-----------------------------------------------+----------+-------------+------------------------------------------------+---------")
+		for _, t := range rows {
+			fmt.Printf("%-46s | %6.2f%%  | %5.1f ±%4.1f | %-46s | %6.2f%%\n",
+				t.Team, 100*t.WinRate, t.Rating.Mu, t.Rating.Sigma, t.HardestCounter, 100*t.CounterWinRate)
+		}
+	}
+	list("Best teams", standings[:n])
+	list("Worst teams", standings[len(standings)-n:])
+}
+
+// printContributions lists each character's Shapley contribution to its
+// teams' win rate next to what its teammates contributed. With only one or
+// two teams there is nothing to separate, so it stays quiet.
+func printContributions(r *sim.Report) {
+	if len(r.Teams) < 3 {
+		return
+	}
+	fmt.Println("\nContribution to team win rate (Shapley)")
+	fmt.Println("Character              | Win%     | Own      | Teammates |")
+	fmt.Println("-----------------------+----------+----------+-----------+----------------")
+	for _, c := range r.Contributions {
+		note := "pulling weight"
+		switch {
+		case c.Carried():
+			note = "being carried"
+		case c.Shapley < 0:
+			note = "holding team back"
+		}
+		fmt.Printf("%-22s | %6.2f%%  | %+7.2f%% | %+8.2f%% | %s\n",
+			c.ID, 100*c.WinRate, 100*c.Shapley, 100*c.Teammates, note)
+	}
+}
+
+// printSynergies lists the n pairs that beat their expected win rate by the
+// most and the n that fall furthest short. A * marks a gap outside the
+// pair's confidence interval.
+func printSynergies(r *sim.Report, n int) {
+	pairs := r.Synergies
+	if n <= 0 || len(pairs) < 2 || len(r.Teams) < 3 {
+		return
+	}
+	n = min(n, len(pairs)/2)
+
+	list := func(title string, rows []sim.Synergy) {
+		fmt.Printf("\n%s\n", title)
+		fmt.Println("Pair                                           | Win%     | Expected | Lift")
+		fmt.Println("-----------------------------------------------+----------+----------+---------")
+		for _, p := range rows {
+			mark := ""
+			if p.Significant {
+				mark = " *"
+			}
+			fmt.Printf("%-46s | %6.2f%%  | %6.2f%%  | %+6.2f%%%s\n",
+				p.A+" + "+p.B, 100*p.WinRate, 100*p.Expected, 100*p.Lift, mark)
+		}
+	}
+	list("Best pairs (synergy)", pairs[:n])
+	list("Worst pairs (anti-synergy)", pairs[len(pairs)-n:])
+}
+
+// printAbilityTable lists every ability's telemetry summed over the
+// characters that have it, then any character that never uses one it has.
+func printAbilityTable(r *sim.Report) {
+	fmt.Println("\nAbility                | Uses/btl | Miss%   | Crit%   | Debuff% | Dmg/use  | Heal/use | DoT/use  | Mana/use")
+	fmt.Println("-----------------------+----------+---------+---------+---------+----------+----------+----------+---------")
+	for _, a := range r.AbilityTotals {
+		fmt.Printf("%-22s | %8.2f | %6.2f%% | %6.2f%% | %6.2f%% | %8.1f | %8.1f | %8.1f | %+7.2f\n",
+			a.Ability, a.UsesPerBattle, 100*a.MissRate, 100*a.CritRate, 100*a.DebuffRate,
+			a.Damage, a.Healing, a.DoT, a.ManaGained-a.ManaSpent)
+	}
+
+	var dead []string
+	for _, a := range r.Abilities {
+		if a.Dead() {
+			dead = append(dead, a.Character+"/"+a.Ability)
+		}
+	}
+	if len(dead) > 0 {
+		fmt.Printf("\nNever used: %s\n", strings.Join(dead, ", "))
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"aethersim/sim"
+)
+
+// runSensitivity nudges each chosen field up and down and ranks the fields
+// by how far that moves win rates.
+func runSensitivity(args []string) int {
+	var o options
+	fs := flag.NewFlagSet("sensitivity", flag.ExitOnError)
+	o.addCommon(fs)
+	o.addBatch(fs)
+	var t tuning
+	t.add(fs, "character.*.*,ability.*.*")
+	delta := fs.Float64("delta", 0, "perturb each field by this fraction of its value; 0 uses each field's step (10%, evasion 0.02, mana cost 1)")
+	abs := fs.Float64("abs", 0, "perturb each field by this amount instead, e.g. 5 for ±5 power; pair it with -params over like fields")
+	fs.Parse(args)
+	if *delta > 0 && *abs > 0 {
+		return fail(usageError{fmt.Errorf("-delta and -abs can't be used together")})
+	}
+	if err := t.setup(&o); err != nil {
+		return fail(err)
+	}
+	fmt.Fprintf(os.Stderr, "Perturbing %d parameters, %d battles each way…\n",
+		len(t.params), len(t.levels)*len(t.batch.Matchups)*o.trials)
+
+	start := time.Now()
+	res, err := sim.Sensitivities(t.pack, sim.SensitivityConfig{
+		Batch:    t.batch,
+		Levels:   t.levels,
+		Params:   t.params,
+		Relative: *delta,
+		Absolute: *abs,
+		Progress: func(done, total int) { drawProgress(done, total, start) },
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	switch o.format {
+	case "json":
+		err = writeJSON(res)
+	case "csv", "markdown":
+		var rows [][]string
+		for _, s := range res {
+			rows = append(rows, []string{s.Param.Name, sim.FormatFloat(s.Value), sim.FormatFloat(s.Delta), strings.Join(s.Owners, " "),
+				sim.FormatFloat(s.OwnerDown), sim.FormatFloat(s.OwnerUp), sim.FormatFloat(s.Effect), sim.FormatFloat(s.Meta)})
+		}
+		err = sim.WriteTable(os.Stdout, o.format, []string{"param", "value", "delta", "owners", "owner_down", "owner_up", "effect", "meta"}, rows)
+	default:
+		printSensitivities(res, o.top)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+// printSensitivities lists the n highest-leverage parameters, or all of them
+// if n is 0.
+func printSensitivities(res []sim.Sensitivity, n int) {
+	if n > 0 && n < len(res) {
+		res = res[:n]
+	}
+	fmt.Println("\nParameter                                        | Value    | ±Delta   | Owner Win% −/+      | Effect   | Meta")
+	fmt.Println("-------------------------------------------------+----------+----------+---------------------+----------+--------")
+	for _, s := range res {
+		fmt.Printf("%-48s | %8.2f | %8.2f | %6.2f%% / %6.2f%%  | %+6.2f%%  | %5.2f%%\n",
+			s.Param.Name, s.Value, s.Delta, 100*s.OwnerDown, 100*s.OwnerUp, 100*s.Effect, 100*s.Meta)
+	}
+}
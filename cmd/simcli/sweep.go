@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"aethersim/sim"
+)
+
+// runSweep plays the same matchups at every level of a range and charts each
+// character's win rate along the progression curve.
+func runSweep(args []string) int {
+	var o options
+	fs := flag.NewFlagSet("sweep", flag.ExitOnError)
+	o.addCommon(fs)
+	o.addBatch(fs)
+	levels := fs.String("levels", "1-10", "ally levels to play, e.g. 1-10 or 2,4,6")
+	offset := fs.Int("enemy-offset", 0, "enemy level relative to the ally level, e.g. 2 for fights two levels over")
+	teamSize := fs.Int("team-size", TEAM_SIZE, "characters per team")
+	matchups := fs.Int("matchups", 2000, "random matchups played per level; 0 plays the full round-robin")
+	jump := fs.Float64("jump", sim.DefaultJumpThreshold, "win-rate change between levels worth flagging")
+	fs.Parse(args)
+	if err := o.setup(); err != nil {
+		return fail(err)
+	}
+	lvls, err := parseLevels(*levels, o.level)
+	if err != nil {
+		return fail(fmt.Errorf("-levels: %w", err))
+	}
+
+	roster := sim.AllCharacterKeys()
+	if err := checkTeamSize(*teamSize, roster); err != nil {
+		return fail(err)
+	}
+	teams := sim.UniqueTeams(roster, *teamSize)
+	batch := o.batchConfig(nil)
+	batch.Progress = nil
+	if *matchups > 0 {
+		batch.Matchups = sim.SampleMatchups(teams, *matchups, o.seed)
+	} else {
+		batch.Matchups = sim.RoundRobin(teams)
+	}
+	fmt.Fprintf(os.Stderr, "Sweeping %d levels, %d matchups each…\n", len(lvls), len(batch.Matchups))
+
+	start := time.Now()
+	res, err := sim.SweepLevels(sim.SweepConfig{
+		Batch:         batch,
+		Levels:        lvls,
+		EnemyOffset:   *offset,
+		JumpThreshold: *jump,
+		Progress:      func(done, total int) { drawProgress(done, total, start) },
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	switch o.format {
+	case "json":
+		err = writeJSON(res)
+	case "csv", "markdown":
+		var rows [][]string
+		for _, l := range res.Levels {
+			for _, id := range sim.AllCharacterKeys() {
+				p, ok := l.Characters[id]
+				if !ok {
+					continue
+				}
+				rows = append(rows, []string{strconv.Itoa(l.AllyLevel), strconv.Itoa(l.EnemyLevel), id,
+					strconv.Itoa(p.Battles), sim.FormatFloat(p.WinRate), sim.FormatFloat(p.WinRateCI.Lo), sim.FormatFloat(p.WinRateCI.Hi),
+					sim.FormatFloat(p.Rating.Mu), sim.FormatFloat(p.Rating.Sigma), strings.Join(p.Unlocked, " ")})
+			}
+		}
+		err = sim.WriteTable(os.Stdout, o.format, []string{"level", "enemy_level", "id", "battles", "win_rate", "win_rate_lo",
+			"win_rate_hi", "rating_mu", "rating_sigma", "unlocked"}, rows)
+	default:
+		printSweep(res)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+// printSweep prints a character × level grid of win rates, with a * where
+// the character unlocked an ability, then the flagged jumps with their
+// intervals and a * on those that are significant.
+func printSweep(res *sim.SweepResult) {
+	fmt.Printf("\n%-22s |", "Character")
+	for _, l := range res.Levels {
+		head := strconv.Itoa(l.AllyLevel)
+		if l.EnemyLevel != l.AllyLevel {
+			head += "v" + strconv.Itoa(l.EnemyLevel)
+		}
+		fmt.Printf(" %7s |", "Lv"+head)
+	}
+	fmt.Println()
+	fmt.Println(strings.Repeat("-", 23) + strings.Repeat("+---------", len(res.Levels)) + "+")
+	for _, id := range sim.AllCharacterKeys() {
+		fmt.Printf("%-22s |", id)
+		for _, l := range res.Levels {
+			p, ok := l.Characters[id]
+			if !ok || p.Battles == 0 {
+				fmt.Printf(" %7s |", "N/A")
+				continue
+			}
+			mark := " "
+			if len(p.Unlocked) > 0 {
+				mark = "*"
+			}
+			fmt.Printf(" %5.1f%%%s |", 100*p.WinRate, mark)
+		}
+		fmt.Println()
+	}
+
+	if len(res.Jumps) == 0 {
+		fmt.Println("\nNo jumps between levels.")
+		return
+	}
+	fmt.Println("\nJumps (* where the change is outside what the two levels' intervals allow)")
+	for _, j := range res.Jumps {
+		cause := "no unlock of its own"
+		if len(j.Unlocked) > 0 {
+			cause = "unlocks " + strings.Join(j.Unlocked, ", ")
+		}
+		mark := " "
+		if j.Significant {
+			mark = "*"
+		}
+		fmt.Printf("  %-22s Lv%d→%d  %+6.2f%%%s (%+.2f to %+.2f%%)  %s\n",
+			j.ID, j.From, j.To, 100*j.Change, mark, 100*j.ChangeCI.Lo, 100*j.ChangeCI.Hi, cause)
+	}
+}
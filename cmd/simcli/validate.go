@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"aethersim/sim"
+)
+
+// runValidate checks the data pack in -data (or the built-in data if it is
+// empty), prints every problem and returns the process exit code.
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	dataDir := fs.String("data", "", "directory holding a data pack; built-in data if empty")
+	fs.Parse(args)
+	dir := *dataDir
+
+	pack := sim.DefaultPack()
+	source := "built-in data"
+	if dir != "" {
+		var err error
+		if pack, err = sim.ReadDataPack(dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		source = dir
+	}
+
+	errs := pack.Validate()
+	for _, e := range errs {
+		fmt.Println(e)
+	}
+	if len(errs) > 0 {
+		fmt.Printf("\n%s: %d problem(s)\n", source, len(errs))
+		return 1
+	}
+	fmt.Printf("%s: %d characters, %d abilities, %d elements, no problems\n",
+		source, len(pack.Characters), len(pack.Abilities), len(pack.Elements))
+	return 0
+}
@@ -1,3 +1,5 @@
 module aethersim
 
 go 1.24.2
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -2,33 +2,39 @@ package sim
 
 // Buff represents a temporary, positive modifier.
 type Buff struct {
-	Type            string  // "defense", "strength", etc.
-	Rounds          int     // number of turns it lasts
-	ModifierPercent float64 // e.g. +25 for +25%
+	Type            string  `json:"type"`             // "defense", "strength", etc.
+	Rounds          int     `json:"rounds"`           // number of turns it lasts
+	ModifierPercent float64 `json:"modifier_percent"` // e.g. +25 for +25%
 }
 
 // Debuff represents a temporary, negative effect (or DoT).
 type Debuff struct {
-	Type              string  // "poison", "burn", "defense", etc.
-	Element           Element // element for elemental debuffs
-	Rounds            int     // duration in turns
-	DamagePercent     float64 // percentage of max HP per tick, if any
-	ModifierPercent   float64 // e.g. –25 for –25% reduction
-	ApplicationChance float64 // percent chance to land
-	ElementToApply    Element // element to apply as debuff, if any
+	Type              string  `json:"type"`                       // "poison", "burn", "defense", etc.
+	Element           Element `json:"element,omitempty"`          // element for elemental debuffs
+	Rounds            int     `json:"rounds"`                     // duration in rounds; control debuffs count the target's own turns
+	DamagePercent     float64 `json:"damage_percent,omitempty"`   // percentage of max HP per tick, if any
+	ModifierPercent   float64 `json:"modifier_percent,omitempty"` // e.g. –25 for –25% reduction
+	ApplicationChance float64 `json:"application_chance"`         // percent chance to land
+	ElementToApply    Element `json:"element_to_apply,omitempty"` // element to apply as debuff, if any
 }
 
 // Ability is the core data you need for simulation.
 type Ability struct {
-	ID               string  // unique key
-	Power            float64 // base dmg or heal amount
-	Buff             *Buff   // non-nil if this is a buff ability
-	Debuff           *Debuff // non-nil if this is a debuff ability
-	Type             string  // "attack", "heal", "buff", "debuff"
-	TargetType       string  // "single", "all", "self"
-	TargetSelectType string  // "ally", "enemy", "any"
-	ManaCost         float64 // e.g. 2 or –1 if none
-	Element          Element // elemental affiliation
+	ID               string  `json:"id"`                 // unique key
+	Power            float64 `json:"power"`              // base dmg or heal amount
+	Buff             *Buff   `json:"buff,omitempty"`     // non-nil if this is a buff ability
+	Debuff           *Debuff `json:"debuff,omitempty"`   // non-nil if this is a debuff ability
+	Type             string  `json:"type"`               // "attack", "heal", "buff", "debuff"
+	TargetType       string  `json:"target_type"`        // "single", "all", "self"
+	TargetSelectType string  `json:"target_select_type"` // "ally", "enemy", "any"
+	ManaCost         float64 `json:"mana_cost"`          // e.g. 2 or –1 if none
+	Element          Element `json:"element"`            // elemental affiliation
+
+	// Readiness limits, counted in the user's own turns; see Character.Ready.
+	Cooldown        int `json:"cooldown,omitempty"`         // turns before it can be used again
+	InitialCooldown int `json:"initial_cooldown,omitempty"` // turns before it can be used at all
+	Uses            int `json:"uses,omitempty"`             // times it can be used per battle; 0 means no limit
+	ChargeTurns     int `json:"charge_turns,omitempty"`     // turns between committing to it and its effect
 }
 
 // AbilityDict lets you look up an Ability by its key.
@@ -6,20 +6,16 @@ import "math/rand"
 func RandomDecision(
 	actor *Character,
 	allies, enemies []*Character,
+	rng *rand.Rand,
 ) (*Ability, []*Character) {
-	// 1) filter abilities by mana
-	usable := make([]*Ability, 0, len(actor.Abilities))
-	for _, ab := range actor.Abilities {
-		if actor.Mana >= ab.ManaCost {
-			usable = append(usable, ab)
-		}
-	}
+	// 1) filter abilities by mana and silence
+	usable := UsableAbilities(actor)
 	if len(usable) == 0 {
 		return nil, nil
 	}
 
 	// 2) pick one at random
-	ab := usable[rand.Intn(len(usable))]
+	ab := usable[rng.Intn(len(usable))]
 
 	// 3) select targets based on ab.TargetType / ab.TargetSelectType
 	var pool []*Character
@@ -39,6 +35,7 @@ func RandomDecision(
 			} else {
 				pool = allies
 			}
+			pool = TargetPool(actor, ab, pool)
 		} else {
 			pool = append(allies, enemies...)
 		}
@@ -47,7 +44,7 @@ func RandomDecision(
 			return nil, nil
 		}
 		// only one target
-		return ab, []*Character{pool[rand.Intn(len(pool))]}
+		return ab, []*Character{pool[rng.Intn(len(pool))]}
 	case "all":
 		if ab.TargetSelectType == "ally" {
 			if actor.IsAlly {
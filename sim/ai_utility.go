@@ -3,21 +3,98 @@ package sim
 import (
 	"math"
 	"math/rand"
+	"slices"
 )
 
+// CONTROL_SCORE is what UtilityDecision reckons one turn lost to crowd
+// control is worth, next to an attack's Power.
+const CONTROL_SCORE = 15.0
+
+// controlTurns is how many lost turns UtilityDecision expects from control
+// debuff d. Sleep breaks on the next hit, so it is good for a turn at most,
+// and silence and taunt only take some of the target's options away.
+func controlTurns(d *Debuff) float64 {
+	switch d.Type {
+	case DebuffSleep:
+		return min(float64(d.Rounds), 1)
+	case DebuffSilence, DebuffTaunt:
+		return 0.5 * float64(d.Rounds)
+	}
+	return float64(d.Rounds)
+}
+
 // UtilityDecision scores every (ability, target) combo and does a roulette‐wheel pick.
 func UtilityDecision(
 	actor *Character,
 	allies, enemies []*Character,
+	rng *rand.Rand,
 ) (*Ability, []*Character) {
-	// 1) filter by usable mana
-	var usable []*Ability
-	for i := range actor.Abilities {
-		ab := actor.Abilities[i]
-		if actor.Mana >= ab.ManaCost {
-			usable = append(usable, ab)
+	heal, combos := scoreAllCombos(actor, allies, enemies, rng)
+	if heal != nil {
+		return heal, []*Character{actor}
+	}
+	if len(combos) == 0 {
+		return nil, nil
+	}
+
+	// 3) roulette‐wheel
+	total := 0.0
+	for _, c := range combos {
+		if c.score > 0 {
+			total += c.score
+		}
+	}
+	if total > 0 {
+		roll := rng.Float64() * total
+		for _, c := range combos {
+			if c.score <= 0 {
+				continue
+			}
+			roll -= c.score
+			if roll <= 0 {
+				return c.ab, []*Character{c.tgt}
+			}
 		}
 	}
+
+	// 4) fallback: best‐score
+	best := bestCombo(combos)
+	return best.ab, []*Character{best.tgt}
+}
+
+// GreedyDecision scores combos like UtilityDecision but always takes the best one.
+func GreedyDecision(
+	actor *Character,
+	allies, enemies []*Character,
+	rng *rand.Rand,
+) (*Ability, []*Character) {
+	heal, combos := scoreAllCombos(actor, allies, enemies, rng)
+	if heal != nil {
+		return heal, []*Character{actor}
+	}
+	if len(combos) == 0 {
+		return nil, nil
+	}
+	best := bestCombo(combos)
+	return best.ab, []*Character{best.tgt}
+}
+
+type utilityCombo struct {
+	ab    *Ability
+	tgt   *Character
+	score float64
+}
+
+// scoreAllCombos filters usable abilities and scores each (ability, target)
+// pair. If the actor is low on health and can heal, it returns that heal
+// instead of any combos.
+func scoreAllCombos(
+	actor *Character,
+	allies, enemies []*Character,
+	rng *rand.Rand,
+) (*Ability, []utilityCombo) {
+	// 1) filter by usable mana and silence
+	usable := UsableAbilities(actor)
 	if len(usable) == 0 {
 		return nil, nil
 	}
@@ -26,18 +103,13 @@ func UtilityDecision(
 	if actor.Health < 0.3*actor.MaxHealth {
 		for _, ab := range usable {
 			if ab.Type == "heal" {
-				return ab, []*Character{actor}
+				return ab, nil
 			}
 		}
 	}
 
 	// 2) build combos
-	type combo struct {
-		ab    *Ability
-		tgt   *Character
-		score float64
-	}
-	var combos []combo
+	var combos []utilityCombo
 
 	for _, ab := range usable {
 		// pick pool
@@ -55,6 +127,7 @@ func UtilityDecision(
 			} else {
 				pool = allies
 			}
+			pool = TargetPool(actor, ab, pool)
 		}
 		for _, tgt := range pool {
 			if tgt.Health <= 0 {
@@ -64,42 +137,22 @@ func UtilityDecision(
 			if ab.Type == "heal" && tgt.Health >= tgt.MaxHealth {
 				continue
 			}
-			s := scoreCombo(actor, ab, tgt, allies, enemies)
-			combos = append(combos, combo{ab, tgt, s})
-		}
-	}
-	if len(combos) == 0 {
-		return nil, nil
-	}
-
-	// 3) roulette‐wheel
-	total := 0.0
-	for _, c := range combos {
-		if c.score > 0 {
-			total += c.score
-		}
-	}
-	if total > 0 {
-		roll := rand.Float64() * total
-		for _, c := range combos {
-			if c.score <= 0 {
-				continue
-			}
-			roll -= c.score
-			if roll <= 0 {
-				return c.ab, []*Character{c.tgt}
-			}
+			s := scoreCombo(actor, ab, tgt, allies, enemies, rng)
+			combos = append(combos, utilityCombo{ab, tgt, s})
 		}
 	}
+	return nil, combos
+}
 
-	// 4) fallback: best‐score
+// bestCombo returns the highest-scoring combo, the first on ties.
+func bestCombo(combos []utilityCombo) utilityCombo {
 	best := combos[0]
 	for _, c := range combos[1:] {
 		if c.score > best.score {
 			best = c
 		}
 	}
-	return best.ab, []*Character{best.tgt}
+	return best
 }
 
 // scoreCombo is the direct translation of your evaluateCombo logic.
@@ -108,6 +161,7 @@ func scoreCombo(
 	ab *Ability,
 	tgt *Character,
 	allies, enemies []*Character,
+	rng *rand.Rand,
 ) float64 {
 	score := 0.0
 
@@ -128,13 +182,16 @@ func scoreCombo(
 		d := ab.Debuff
 		if d.DamagePercent > 0 {
 			score += (d.ApplicationChance / 100.0) * d.DamagePercent * float64(d.Rounds)
+		} else if slices.Contains(ControlDebuffTypes, d.Type) {
+			score += (d.ApplicationChance / 100.0) * CONTROL_SCORE * controlTurns(d)
 		} else if d.ModifierPercent > 0 {
 			score += (d.ApplicationChance / 100.0) * (d.ModifierPercent * 0.3) * float64(d.Rounds)
 		}
 		score += 10 // straight debuff bonus
 
 	default: // Attack
-		base := float64(ab.Power)
+		// expected damage, counting the chance of a crit
+		base := float64(ab.Power) * (1 + CritChance(src)*(CRIT_MULTIPLIER-1))
 		bonus := 0.0
 		if tgt.Health/tgt.MaxHealth < 0.3 {
 			bonus = 0.5
@@ -163,7 +220,7 @@ func scoreCombo(
 	// element
 	if ab.Type == "attack" {
 		weak, resist := false, false
-		for _, el := range tgt.Elements {
+		for _, el := range tgt.CurrentElements() {
 			for _, w := range GetWeaknesses(el) {
 				if w == ab.Element {
 					weak = true
@@ -203,6 +260,9 @@ func scoreCombo(
 		score *= 1 + -costNorm*inner
 	}
 
+	// charge: the payoff comes turns later
+	score *= math.Pow(CHARGE_DISCOUNT, float64(ab.ChargeTurns))
+
 	// repeat‐debuff
 	if ab.Type == "debuff" {
 		for _, inst := range tgt.ActiveDebuffs {
@@ -217,7 +277,7 @@ func scoreCombo(
 	}
 
 	// randomness
-	score += rand.Float64() * 0.1
+	score += rng.Float64() * 0.1
 
 	return score
 }
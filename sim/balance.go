@@ -0,0 +1,160 @@
+package sim
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+)
+
+// BalanceConfig describes an auto-balancing run.
+type BalanceConfig struct {
+	// Batch is played in full for every evaluation of the objective, once per
+	// level. It must list its Matchups; SampleMatchups keeps them affordable.
+	// Its seed stays fixed, so every candidate faces the same dice and small
+	// differences between candidates aren't drowned by noise.
+	Batch BatchConfig
+
+	Levels     []int   // levels to balance at, both sides; Batch.AllyLevel if empty
+	Params     []Param // what may change
+	Iterations int     // passes over Params; 0 means 5
+	Target     float64 // win rate every character is pushed toward; 0 means 0.5
+
+	// Progress, if set, is called after each parameter is tried with the
+	// objective so far.
+	Progress func(iteration int, p Param, loss float64)
+}
+
+// ParamChange is one suggested edit.
+type ParamChange struct {
+	Param Param   `json:"param"`
+	Old   float64 `json:"old"`
+	New   float64 `json:"new"`
+}
+
+// BalanceResult is the outcome of Balance.
+type BalanceResult struct {
+	Pack        *DataPack          `json:"-"` // the tuned pack
+	Changes     []ParamChange      `json:"changes"`
+	LossBefore  float64            `json:"loss_before"` // mean squared distance from Target
+	LossAfter   float64            `json:"loss_after"`
+	Before      map[string]float64 `json:"before"` // win rate per character
+	After       map[string]float64 `json:"after"`
+	Evaluations int                `json:"evaluations"`
+}
+
+// Balance tunes cfg.Params in a clone of pack to bring every character's win
+// rate toward cfg.Target, by coordinate descent: each parameter in turn is
+// moved one step up or down, whichever lowers the mean squared distance from
+// the target, and its step is halved when neither does.
+//
+// Candidates are installed while they are evaluated; whatever data was
+// installed before is put back when Balance returns.
+func Balance(pack *DataPack, cfg BalanceConfig) (*BalanceResult, error) {
+	if len(cfg.Params) == 0 {
+		return nil, fmt.Errorf("nothing to tune")
+	}
+	if len(cfg.Batch.Matchups) == 0 {
+		return nil, fmt.Errorf("no matchups to evaluate")
+	}
+	levels := cfg.Levels
+	if len(levels) == 0 {
+		levels = []int{cfg.Batch.AllyLevel}
+	}
+	iterations := cfg.Iterations
+	if iterations <= 0 {
+		iterations = 5
+	}
+	target := cfg.Target
+	if target == 0 {
+		target = 0.5
+	}
+
+	prev := CurrentPack()
+	defer prev.Install()
+	work := pack.Clone()
+	work.Install()
+
+	out := &BalanceResult{Pack: work}
+	evaluate := func() (float64, map[string]float64, error) {
+		out.Evaluations++
+		rates, err := balanceWinRates(cfg.Batch, levels)
+		if err != nil {
+			return 0, nil, err
+		}
+		loss := 0.0
+		for _, id := range slices.Sorted(maps.Keys(rates)) {
+			loss += (rates[id] - target) * (rates[id] - target)
+		}
+		return loss / float64(len(rates)), rates, nil
+	}
+
+	best, rates, err := evaluate()
+	if err != nil {
+		return nil, err
+	}
+	out.LossBefore, out.Before = best, rates
+
+	steps := make([]float64, len(cfg.Params))
+	for i, p := range cfg.Params {
+		steps[i] = p.Step
+	}
+	for it := 0; it < iterations; it++ {
+		for i, p := range cfg.Params {
+			cur := p.Get(work)
+			bestV := cur
+			for _, dir := range []float64{1, -1} {
+				v := min(max(cur+dir*steps[i], p.Min), p.Max)
+				if v == cur {
+					continue
+				}
+				p.Set(work, v)
+				loss, r, err := evaluate()
+				if err != nil {
+					return nil, err
+				}
+				if loss < best {
+					best, bestV, rates = loss, v, r
+				}
+			}
+			p.Set(work, bestV)
+			// mana and turns come in whole units, so those keep whole steps
+			if bestV == cur && !p.Whole {
+				steps[i] /= 2
+			}
+			if cfg.Progress != nil {
+				cfg.Progress(it, p, best)
+			}
+		}
+	}
+	out.LossAfter, out.After = best, rates
+
+	for _, p := range cfg.Params {
+		if old, now := p.Get(pack), p.Get(work); math.Abs(now-old) > 1e-9 {
+			out.Changes = append(out.Changes, ParamChange{Param: p, Old: old, New: now})
+		}
+	}
+	return out, nil
+}
+
+// balanceWinRates plays batch at each level and returns every character's
+// pooled win rate over all of them.
+func balanceWinRates(batch BatchConfig, levels []int) (map[string]float64, error) {
+	wins, battles := map[string]int{}, map[string]int{}
+	for _, lv := range levels {
+		batch.AllyLevel, batch.EnemyLevel = lv, lv
+		res, err := RunBatch(batch)
+		if err != nil {
+			return nil, err
+		}
+		for id, s := range res.Characters {
+			wins[id] += s.Wins
+			battles[id] += s.Battles
+		}
+	}
+	rates := make(map[string]float64, len(wins))
+	for id := range wins {
+		rates[id] = ratio(float64(wins[id]), battles[id])
+	}
+	return rates, nil
+}
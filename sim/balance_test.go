@@ -0,0 +1,115 @@
+package sim
+
+import (
+	"slices"
+	"testing"
+)
+
+// duelPack is a two-character pack for the tuning tests: hero and foe have
+// the same stats and each knows one attack, hero's jab and foe's poke, both
+// of power 30 until a test changes them. Hero also learns zap at level 4.
+func duelPack() *DataPack {
+	fighter := func(attack string) CharacterTemplate {
+		return CharacterTemplate{
+			Elements:   []Element{Earth},
+			BaseHealth: 30, BaseMana: 4, BaseStrength: 5, BaseDefense: 5, BaseSpirit: 5, BaseSpeed: 5,
+			HPGrowth: 4, StrengthGrowth: 2, DefenseGrowth: 2, SpiritGrowth: 2, SpeedGrowth: 1.5,
+			AbilityTemplates: []CharacterAbilityTemplate{{Key: attack, MinLv: 0}},
+		}
+	}
+	hero := fighter("jab")
+	hero.AbilityTemplates = append(hero.AbilityTemplates, CharacterAbilityTemplate{Key: "zap", MinLv: 4})
+	attack := func(id string, power float64) *Ability {
+		return &Ability{ID: id, Power: power, Type: "attack", TargetType: "single", TargetSelectType: "enemy",
+			ManaCost: -1, Element: Earth}
+	}
+	zap := attack("zap", 30)
+	zap.ManaCost = 2
+	return &DataPack{
+		Characters: map[string]CharacterTemplate{"hero": hero, "foe": fighter("poke")},
+		Abilities:  map[string]*Ability{"jab": attack("jab", 30), "poke": attack("poke", 30), "zap": zap},
+		Elements:   DefaultPack().Elements,
+	}
+}
+
+// duelBatch plays hero against foe at level 2.
+func duelBatch(trials int) BatchConfig {
+	return BatchConfig{
+		Matchups:    []Matchup{{A: []string{"hero"}, B: []string{"foe"}}},
+		Trials:      trials,
+		AllyLevel:   2,
+		EnemyLevel:  2,
+		Seed:        3,
+		AllyPolicy:  "utility",
+		EnemyPolicy: "utility",
+	}
+}
+
+// TestBalanceSteps tunes the power of a hero that hits harder than its foe,
+// within bounds of 19.5 and 31.5. Every pass should try the jab one step
+// either side of where it is, clamped to the bounds, and halve the step
+// whenever neither try helps.
+func TestBalanceSteps(t *testing.T) {
+	pack := duelPack()
+	pack.Abilities["poke"].Power = 20
+	params, err := SelectParams(pack, []string{"ability.jab.power"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params[0].Min, params[0].Max = 19.5, 31.5
+	p := params[0]
+
+	// the power each evaluation played with, the first being the baseline
+	var tried []float64
+	batch := duelBatch(64)
+	batch.Progress = func(done, total int) {
+		if done == total {
+			tried = append(tried, p.Get(CurrentPack()))
+		}
+	}
+	before := CurrentPack()
+	cur, step, halved := p.Get(pack), p.Step, 0
+	res, err := Balance(pack, BalanceConfig{
+		Batch:      batch,
+		Params:     params,
+		Iterations: 6,
+		Progress: func(_ int, q Param, _ float64) {
+			var want []float64
+			for _, dir := range []float64{1, -1} {
+				if v := min(max(cur+dir*step, p.Min), p.Max); v != cur {
+					want = append(want, v)
+				}
+			}
+			if got := tried[len(tried)-len(want):]; !slices.Equal(got, want) {
+				t.Errorf("from %g with a step of %g tried %v, want %v", cur, step, got, want)
+			}
+			v := q.Get(CurrentPack())
+			if v == cur {
+				step /= 2
+				halved++
+			}
+			cur = v
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if halved == 0 || halved == 6 {
+		t.Errorf("step halved in %d of 6 passes, want the power to move and then settle (tried %v)", halved, tried)
+	}
+	for _, v := range tried {
+		if v < p.Min || v > p.Max {
+			t.Errorf("tried power %g outside [%g, %g]", v, p.Min, p.Max)
+		}
+	}
+	if res.LossAfter > res.LossBefore {
+		t.Errorf("loss rose from %g to %g", res.LossBefore, res.LossAfter)
+	}
+	if got := p.Get(pack); got != 30 {
+		t.Errorf("the pack passed in changed to power %g", got)
+	}
+	if CurrentPack().Abilities["jab"] != before.Abilities["jab"] {
+		t.Error("the previously installed data wasn't put back")
+	}
+}
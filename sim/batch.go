@@ -0,0 +1,460 @@
+package sim
+
+import (
+	"fmt"
+	"maps"
+	"math/rand"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// CharacterStats accumulates one character's results over many battles.
+type CharacterStats struct {
+	Battles     int
+	Wins        int
+	AllyBattles int // the subset played on the ally side
+	AllyWins    int
+	DamageDealt float64
+	DamageTaken float64
+	HealingDone float64
+	DoTDealt    float64
+}
+
+func (s *CharacterStats) merge(o *CharacterStats) {
+	s.Battles += o.Battles
+	s.Wins += o.Wins
+	s.AllyBattles += o.AllyBattles
+	s.AllyWins += o.AllyWins
+	s.DamageDealt += o.DamageDealt
+	s.DamageTaken += o.DamageTaken
+	s.HealingDone += o.HealingDone
+	s.DoTDealt += o.DoTDealt
+}
+
+// Matchup is one pairing of teams; A plays the ally side, B the enemy side.
+type Matchup struct {
+	A []string
+	B []string
+}
+
+// MatchupResult is the outcome of every trial of one matchup.
+type MatchupResult struct {
+	Matchup  Matchup
+	Battles  int
+	AWins    int
+	Rounds   int
+	Turns    int
+	TimedOut int
+
+	Outcomes []bool // whether A won, per trial in order; feeds Rater
+}
+
+// merge adds o, the trials that follow r's, onto r.
+func (r *MatchupResult) merge(o *MatchupResult) {
+	r.Battles += o.Battles
+	r.AWins += o.AWins
+	r.Rounds += o.Rounds
+	r.Turns += o.Turns
+	r.TimedOut += o.TimedOut
+	r.Outcomes = append(r.Outcomes, o.Outcomes...)
+}
+
+// BatchConfig describes a batch of simulated battles.
+type BatchConfig struct {
+	Matchups    []Matchup
+	Trials      int // battles per matchup; in adaptive mode, the batch size per check
+	AllyLevel   int
+	EnemyLevel  int
+	Seed        int64
+	Workers     int // 0 means one per CPU
+	AllyPolicy  string
+	EnemyPolicy string
+	ATB         bool // initiative timeline; see Engine.UseATB
+
+	// Overrides gives the characters in some slots a policy of their own, by
+	// registry name, in place of their side's.
+	Overrides map[Slot]string
+
+	// Confidence is the level of every interval reported; 0 means 95%.
+	Confidence float64
+
+	// Adaptive mode: when TargetWidth > 0, each matchup keeps playing Trials
+	// more battles until the interval on its win rate is at most TargetWidth
+	// wide or it has played MaxTrials battles.
+	TargetWidth float64
+	MaxTrials   int
+
+	// Progress, if set, is called from RunBatch's goroutine with the number of
+	// battles finished so far.
+	Progress func(done, total int)
+}
+
+// BatchResult aggregates every battle of a batch.
+type BatchResult struct {
+	Seed        int64
+	Confidence  float64
+	Characters  map[string]*CharacterStats
+	Abilities   Telemetry
+	Lengths     Distributions
+	Matchups    []MatchupResult // same order as BatchConfig.Matchups
+	Battles     int
+	TotalRounds int
+	TotalTurns  int
+}
+
+// batchBlockSize is how many battles a worker takes at a time. Blocks are cut
+// at fixed points of the run of (matchup, trial) pairs regardless of worker
+// count, and merged in order, so floating-point sums come out identical
+// however many workers run, and a single matchup still spreads over all of
+// them.
+const batchBlockSize = 64
+
+// segment is trials [from, to) of matchup m.
+type segment struct {
+	m, from, to int
+}
+
+// blockResult is one worker's private tally for one block of battles.
+type blockResult struct {
+	segments   []segment
+	matchups   []MatchupResult // one per segment
+	characters map[string]*CharacterStats
+	abilities  Telemetry
+	lengths    Distributions
+}
+
+// RunBatch simulates cfg.Trials battles of every matchup, fanned out over
+// cfg.Workers goroutines. Battle t of matchup m is seeded with
+// BattleSeed(cfg.Seed, m, t), so results depend only on the config, never on
+// the worker count or scheduling.
+func RunBatch(cfg BatchConfig) (*BatchResult, error) {
+	if _, err := NewTeamPolicies(cfg.AllyPolicy, cfg.EnemyPolicy, cfg.Overrides); err != nil {
+		return nil, err
+	}
+	if cfg.Trials <= 0 {
+		return nil, fmt.Errorf("trials must be positive, got %d", cfg.Trials)
+	}
+	if cfg.Confidence == 0 {
+		cfg.Confidence = DefaultConfidence
+	}
+	if cfg.Confidence < 0 || cfg.Confidence >= 1 {
+		return nil, fmt.Errorf("confidence must be in (0, 1), got %g", cfg.Confidence)
+	}
+	if cfg.TargetWidth > 0 && cfg.MaxTrials < cfg.Trials {
+		return nil, fmt.Errorf("max trials (%d) must be at least trials (%d) in adaptive mode", cfg.MaxTrials, cfg.Trials)
+	}
+	// build every character once up front, so runBlock can't fail
+	keys := map[string]bool{}
+	for _, m := range cfg.Matchups {
+		for _, key := range slices.Concat(m.A, m.B) {
+			keys[key] = true
+		}
+	}
+	if _, err := MakeTeam(slices.Sorted(maps.Keys(keys)), cfg.AllyLevel, true); err != nil {
+		return nil, err
+	}
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	res := &BatchResult{
+		Seed:       cfg.Seed,
+		Confidence: cfg.Confidence,
+		Characters: map[string]*CharacterStats{},
+		Abilities:  Telemetry{},
+		Matchups:   make([]MatchupResult, len(cfg.Matchups)),
+	}
+	// in adaptive mode progress counts each matchup's whole budget, spent or not
+	perMatchup := cfg.Trials
+	if cfg.TargetWidth > 0 {
+		perMatchup = cfg.MaxTrials
+	}
+	total := len(cfg.Matchups) * perMatchup
+	finished := 0
+	report := func(n int) {
+		finished += n
+		if cfg.Progress != nil {
+			cfg.Progress(finished, total)
+		}
+	}
+
+	// Play in rounds of Trials more battles for every matchup still going.
+	// Without a TargetWidth the first round is the whole batch; in adaptive
+	// mode a matchup only stops between rounds, once its interval is narrow
+	// enough, so where it stops doesn't depend on scheduling either.
+	active := make([]int, len(cfg.Matchups))
+	for mi, m := range cfg.Matchups {
+		active[mi] = mi
+		res.Matchups[mi].Matchup = m
+	}
+	for len(active) > 0 {
+		segs := make([]segment, 0, len(active))
+		for _, mi := range active {
+			played := res.Matchups[mi].Battles
+			n := cfg.Trials
+			if cfg.TargetWidth > 0 {
+				n = min(n, cfg.MaxTrials-played)
+			}
+			segs = append(segs, segment{m: mi, from: played, to: played + n})
+		}
+
+		// merge in block order so the sums don't depend on scheduling
+		for _, b := range runBlocks(cfg, splitBlocks(segs), workers, report) {
+			for i, s := range b.segments {
+				res.Matchups[s.m].merge(&b.matchups[i])
+			}
+			for _, id := range slices.Sorted(maps.Keys(b.characters)) {
+				rec, ok := res.Characters[id]
+				if !ok {
+					rec = &CharacterStats{}
+					res.Characters[id] = rec
+				}
+				rec.merge(b.characters[id])
+			}
+			for key, s := range b.abilities {
+				res.Abilities.stats(key.Character, key.Ability).merge(s)
+			}
+			res.Lengths.merge(&b.lengths)
+		}
+
+		next := active[:0]
+		for _, mi := range active {
+			mr := &res.Matchups[mi]
+			if !matchupDone(cfg, mr) {
+				next = append(next, mi)
+			} else if unspent := perMatchup - mr.Battles; unspent > 0 {
+				report(unspent)
+			}
+		}
+		active = next
+	}
+	for _, m := range res.Matchups {
+		res.Battles += m.Battles
+		res.TotalRounds += m.Rounds
+		res.TotalTurns += m.Turns
+	}
+	return res, nil
+}
+
+// splitBlocks cuts segs into blocks of batchBlockSize battles, splitting a
+// segment wherever a block boundary falls inside it.
+func splitBlocks(segs []segment) [][]segment {
+	var blocks [][]segment
+	var cur []segment
+	size := 0
+	for _, s := range segs {
+		for s.from < s.to {
+			n := min(s.to-s.from, batchBlockSize-size)
+			cur = append(cur, segment{m: s.m, from: s.from, to: s.from + n})
+			s.from += n
+			if size += n; size == batchBlockSize {
+				blocks = append(blocks, cur)
+				cur, size = nil, 0
+			}
+		}
+	}
+	if len(cur) > 0 {
+		blocks = append(blocks, cur)
+	}
+	return blocks
+}
+
+// runBlocks plays every block on workers goroutines and returns their
+// results in block order, calling report with the battles of each block as
+// it finishes.
+func runBlocks(cfg BatchConfig, blocks [][]segment, workers int, report func(n int)) []blockResult {
+	results := make([]blockResult, len(blocks))
+	jobs := make(chan int)
+	done := make(chan int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range jobs {
+				results[b] = runBlock(cfg, blocks[b])
+				n := 0
+				for _, s := range blocks[b] {
+					n += s.to - s.from
+				}
+				done <- n
+			}
+		}()
+	}
+	go func() {
+		for b := range blocks {
+			jobs <- b
+		}
+		close(jobs)
+		wg.Wait()
+		close(done)
+	}()
+
+	for n := range done {
+		report(n)
+	}
+	return results
+}
+
+// runBlock plays every battle of one block, tallying each segment into a
+// MatchupResult of its own.
+func runBlock(cfg BatchConfig, segs []segment) blockResult {
+	br := blockResult{segments: segs, characters: map[string]*CharacterStats{}, abilities: Telemetry{}}
+	stat := func(id string) *CharacterStats {
+		s, ok := br.characters[id]
+		if !ok {
+			s = &CharacterStats{}
+			br.characters[id] = s
+		}
+		return s
+	}
+
+	for _, seg := range segs {
+		m := cfg.Matchups[seg.m]
+		var mr MatchupResult
+		for t := seg.from; t < seg.to; t++ {
+			aTeam, _ := MakeTeam(m.A, cfg.AllyLevel, true)
+			bTeam, _ := MakeTeam(m.B, cfg.EnemyLevel, false)
+			engine := NewEngine(aTeam, bTeam, BattleSeed(cfg.Seed, seg.m, t))
+			if cfg.ATB {
+				engine.UseATB()
+			}
+			br.abilities.Join(aTeam)
+			br.abilities.Join(bTeam)
+			engine.Events = br.abilities
+			var watch battleWatch
+			// fresh policies per battle, so stateful ones start clean
+			policy, _ := NewTeamPolicies(cfg.AllyPolicy, cfg.EnemyPolicy, cfg.Overrides)
+
+			for !engine.GameOver {
+				engine.Step(policy)
+
+				// record per-target delta stats for this step
+				for _, imp := range engine.LastImpacts {
+					rec := stat(imp.ActorID)
+					if imp.Delta > 0 {
+						rec.DamageDealt += imp.Delta
+						stat(imp.TargetID).DamageTaken += imp.Delta
+						if imp.IsDebuff {
+							rec.DoTDealt += imp.Delta
+						}
+					} else if imp.Delta < 0 {
+						// negative delta means healing
+						rec.HealingDone += -imp.Delta
+					}
+				}
+				watch.observe(engine)
+			}
+			watch.finish(engine, &br.lengths)
+
+			for _, c := range aTeam {
+				s := stat(c.ID)
+				s.Battles++
+				s.AllyBattles++
+				if engine.PlayerWon {
+					s.Wins++
+					s.AllyWins++
+				}
+			}
+			for _, c := range bTeam {
+				s := stat(c.ID)
+				s.Battles++
+				if !engine.PlayerWon {
+					s.Wins++
+				}
+			}
+			mr.Battles++
+			mr.Outcomes = append(mr.Outcomes, engine.PlayerWon)
+			if engine.PlayerWon {
+				mr.AWins++
+			}
+			mr.Rounds += engine.TotalRounds
+			mr.Turns += engine.TotalTurns
+			if engine.TimedOut {
+				mr.TimedOut++
+			}
+		}
+		br.matchups = append(br.matchups, mr)
+	}
+	return br
+}
+
+// matchupDone reports whether a matchup has played enough: Trials battles,
+// or in adaptive mode a narrow enough interval or MaxTrials battles.
+func matchupDone(cfg BatchConfig, mr *MatchupResult) bool {
+	if cfg.TargetWidth <= 0 {
+		return mr.Battles >= cfg.Trials
+	}
+	if mr.Battles >= cfg.MaxTrials {
+		return true
+	}
+	return WilsonInterval(mr.AWins, mr.Battles, cfg.Confidence).Width() <= cfg.TargetWidth
+}
+
+// BattleSeed derives the seed for trial t of matchup m from a base seed, so
+// any single battle of a batch can be re-run on its own.
+func BattleSeed(base int64, m, t int) int64 {
+	x := uint64(base)
+	x = splitmix64(x ^ uint64(m)*0x9e3779b97f4a7c15)
+	x = splitmix64(x ^ uint64(t)*0xbf58476d1ce4e5b9)
+	return int64(x)
+}
+
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// RoundRobin pairs every team with every team at or after it, mirrors included.
+func RoundRobin(teams [][]string) []Matchup {
+	out := make([]Matchup, 0, len(teams)*(len(teams)+1)/2)
+	for i := range teams {
+		for j := i; j < len(teams); j++ {
+			out = append(out, Matchup{A: teams[i], B: teams[j]})
+		}
+	}
+	return out
+}
+
+// SampleMatchups draws n matchups between two different teams picked at
+// random, for batches too large to play as a full round-robin.
+func SampleMatchups(teams [][]string, n int, seed int64) []Matchup {
+	if len(teams) < 2 {
+		return nil
+	}
+	rng := rand.New(rand.NewSource(seed))
+	out := make([]Matchup, n)
+	for i := range out {
+		a := rng.Intn(len(teams))
+		b := rng.Intn(len(teams) - 1)
+		if b >= a {
+			b++
+		}
+		out[i] = Matchup{A: teams[a], B: teams[b]}
+	}
+	return out
+}
+
+// UniqueTeams returns all k‐sized combinations of keys, without repetition.
+func UniqueTeams(keys []string, k int) [][]string {
+	var res [][]string
+	var helper func(start int, combo []string)
+	helper = func(start int, combo []string) {
+		if len(combo) == k {
+			// copy
+			c := make([]string, k)
+			copy(c, combo)
+			res = append(res, c)
+			return
+		}
+		for i := start; i < len(keys); i++ {
+			helper(i+1, append(combo, keys[i]))
+		}
+	}
+	helper(0, []string{})
+	return res
+}
@@ -0,0 +1,109 @@
+package sim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRunBatchDeterministic(t *testing.T) {
+	teams := [][]string{{"mycera", "pondril"}, {"frostnip", "fayluna"}, {"pondril", "frostnip"}}
+	tests := []struct {
+		name string
+		cfg  BatchConfig
+	}{
+		{"one matchup", BatchConfig{
+			Matchups: []Matchup{{A: teams[0], B: teams[1]}},
+			Trials:   300,
+		}},
+		{"round robin", BatchConfig{
+			Matchups: RoundRobin(teams),
+			Trials:   50,
+		}},
+		{"adaptive", BatchConfig{
+			Matchups:    RoundRobin(teams),
+			Trials:      40,
+			TargetWidth: 0.2,
+			MaxTrials:   300,
+		}},
+		{"atb with overrides", BatchConfig{
+			Matchups:  []Matchup{{A: teams[0], B: teams[2]}},
+			Trials:    130,
+			ATB:       true,
+			Overrides: map[Slot]string{{IsAlly: true, ID: "mycera"}: "focus"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want *BatchResult
+			for _, workers := range []int{1, 3, 8} {
+				cfg := tt.cfg
+				cfg.AllyLevel, cfg.EnemyLevel = 5, 5
+				cfg.Seed = 42
+				cfg.AllyPolicy, cfg.EnemyPolicy = "utility", "utility"
+				cfg.Workers = workers
+				last, total := 0, 0
+				cfg.Progress = func(done, tot int) {
+					if done < last {
+						t.Errorf("progress went back from %d to %d", last, done)
+					}
+					last, total = done, tot
+				}
+
+				got, err := RunBatch(cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if last != total {
+					t.Errorf("%d workers: progress ended at %d of %d", workers, last, total)
+				}
+				for _, m := range got.Matchups {
+					if len(m.Outcomes) != m.Battles {
+						t.Errorf("%d workers: %d outcomes for %d battles", workers, len(m.Outcomes), m.Battles)
+					}
+				}
+				if want == nil {
+					want = got
+				} else if !reflect.DeepEqual(got, want) {
+					t.Errorf("%d workers gave different results than 1", workers)
+				}
+			}
+		})
+	}
+}
+
+func TestRunBatchAdaptiveStops(t *testing.T) {
+	cfg := BatchConfig{
+		Matchups:    []Matchup{{A: []string{"mycera"}, B: []string{"frostnip"}}},
+		Trials:      25,
+		AllyLevel:   5,
+		EnemyLevel:  5,
+		AllyPolicy:  "utility",
+		EnemyPolicy: "utility",
+		TargetWidth: 0.3,
+		MaxTrials:   110,
+	}
+	res, err := RunBatch(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := res.Matchups[0].Battles
+	if n%cfg.Trials != 0 && n != cfg.MaxTrials {
+		t.Errorf("stopped after %d battles, not a multiple of %d nor %d", n, cfg.Trials, cfg.MaxTrials)
+	}
+	if n < cfg.MaxTrials && WilsonInterval(res.Matchups[0].AWins, n, DefaultConfidence).Width() > cfg.TargetWidth {
+		t.Errorf("stopped after %d battles with the interval still wider than %g", n, cfg.TargetWidth)
+	}
+}
+
+func TestSplitBlocks(t *testing.T) {
+	segs := []segment{{m: 0, from: 0, to: batchBlockSize + 10}, {m: 1, from: 5, to: 5 + batchBlockSize}}
+	blocks := splitBlocks(segs)
+	want := [][]segment{
+		{{m: 0, from: 0, to: batchBlockSize}},
+		{{m: 0, from: batchBlockSize, to: batchBlockSize + 10}, {m: 1, from: 5, to: 5 + batchBlockSize - 10}},
+		{{m: 1, from: 5 + batchBlockSize - 10, to: 5 + batchBlockSize}},
+	}
+	if !reflect.DeepEqual(blocks, want) {
+		t.Errorf("splitBlocks = %v, want %v", blocks, want)
+	}
+}
@@ -4,26 +4,27 @@ import "sort"
 
 // AbilityTemplate ties an ability key to the minimum level required.
 type CharacterAbilityTemplate struct {
-	Key   string
-	MinLv int
+	Key   string `json:"key"`
+	MinLv int    `json:"min_lv"`
 }
 
 // Template holds all of the static, per‐species data you need to spawn a Character.
 type CharacterTemplate struct {
-	Elements         []Element
-	BaseHealth       float64
-	BaseMana         float64
-	BaseStrength     float64
-	BaseDefense      float64
-	BaseSpirit       float64
-	BaseSpeed        float64
-	HPGrowth         float64
-	StrengthGrowth   float64
-	DefenseGrowth    float64
-	SpiritGrowth     float64
-	SpeedGrowth      float64
-	Evasion          float64
-	AbilityTemplates []CharacterAbilityTemplate
+	Elements         []Element                  `json:"elements"`
+	BaseHealth       float64                    `json:"base_health"`
+	BaseMana         float64                    `json:"base_mana"`
+	BaseStrength     float64                    `json:"base_strength"`
+	BaseDefense      float64                    `json:"base_defense"`
+	BaseSpirit       float64                    `json:"base_spirit"`
+	BaseSpeed        float64                    `json:"base_speed"`
+	HPGrowth         float64                    `json:"hp_growth"`
+	StrengthGrowth   float64                    `json:"strength_growth"`
+	DefenseGrowth    float64                    `json:"defense_growth"`
+	SpiritGrowth     float64                    `json:"spirit_growth"`
+	SpeedGrowth      float64                    `json:"speed_growth"`
+	Evasion          float64                    `json:"evasion"`
+	CritChance       float64                    `json:"crit_chance"` // added to the mana-driven crit chance
+	AbilityTemplates []CharacterAbilityTemplate `json:"abilities"`
 }
 
 // AllCharacterKeys returns a sorted list of all character IDs in the templates map.
@@ -0,0 +1,98 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+)
+
+// DefaultConfidence is the confidence level used when none is configured.
+const DefaultConfidence = 0.95
+
+// DefaultBootstrapSamples is how many resamples BootstrapRatio draws by default.
+const DefaultBootstrapSamples = 200
+
+// MinBootstrapClusters is the fewest clusters worth resampling. With fewer,
+// the handful of possible resamples says little about the spread, and with
+// one every resample is the same and the interval has no width at all.
+const MinBootstrapClusters = 10
+
+// Interval is a two-sided confidence interval.
+type Interval struct {
+	Lo float64 `json:"lo"`
+	Hi float64 `json:"hi"`
+}
+
+// Width is Hi - Lo.
+func (iv Interval) Width() float64 {
+	return iv.Hi - iv.Lo
+}
+
+// zScore returns the two-sided normal quantile for a confidence level in (0,1).
+func zScore(confidence float64) float64 {
+	if confidence <= 0 || confidence >= 1 {
+		confidence = DefaultConfidence
+	}
+	return math.Sqrt2 * math.Erfinv(confidence)
+}
+
+// WilsonInterval is the Wilson score interval for wins successes out of n
+// trials. It behaves well near 0% and 100%, where the normal approximation
+// collapses to a zero-width interval.
+func WilsonInterval(wins, n int, confidence float64) Interval {
+	if n == 0 {
+		return Interval{0, 1}
+	}
+	z := zScore(confidence)
+	p := float64(wins) / float64(n)
+	nf := float64(n)
+	denom := 1 + z*z/nf
+	center := (p + z*z/(2*nf)) / denom
+	half := z * math.Sqrt(p*(1-p)/nf+z*z/(4*nf*nf)) / denom
+	return Interval{math.Max(0, center-half), math.Min(1, center+half)}
+}
+
+// BootstrapRatio estimates an interval for sum(num)/sum(den) by resampling
+// the clusters (num[i], den[i]) with replacement. Matchups make natural
+// clusters: each one's battles share teams and are not independent of each
+// other. rng makes the result reproducible.
+func BootstrapRatio(num, den []float64, samples int, confidence float64, rng *rand.Rand) Interval {
+	n := len(num)
+	if n == 0 {
+		return Interval{}
+	}
+	if samples <= 0 {
+		samples = DefaultBootstrapSamples
+	}
+	if confidence <= 0 || confidence >= 1 {
+		confidence = DefaultConfidence
+	}
+	stats := make([]float64, samples)
+	for s := range stats {
+		var sn, sd float64
+		for i := 0; i < n; i++ {
+			k := rng.Intn(n)
+			sn += num[k]
+			sd += den[k]
+		}
+		if sd > 0 {
+			stats[s] = sn / sd
+		}
+	}
+	alpha := (1 - confidence) / 2
+	return Interval{quantile(stats, alpha), quantile(stats, 1-alpha)}
+}
+
+// quantile sorts xs in place and returns its q-th quantile by linear
+// interpolation.
+func quantile(xs []float64, q float64) float64 {
+	if len(xs) == 0 {
+		return math.NaN()
+	}
+	slices.Sort(xs)
+	pos := q * float64(len(xs)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	frac := pos - float64(lo)
+	return xs[lo]*(1-frac) + xs[hi]*frac
+}
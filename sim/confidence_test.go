@@ -0,0 +1,108 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestZScore(t *testing.T) {
+	tests := []struct {
+		confidence, want float64
+	}{
+		{0.95, 1.959964},
+		{0.99, 2.575829},
+		{0.80, 1.281552},
+		{0, 1.959964}, // out of range falls back to DefaultConfidence
+		{1, 1.959964},
+	}
+	for _, tt := range tests {
+		if got := zScore(tt.confidence); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("zScore(%g) = %.6f, want %.6f", tt.confidence, got, tt.want)
+		}
+	}
+}
+
+func TestWilsonInterval(t *testing.T) {
+	tests := []struct {
+		wins, n    int
+		confidence float64
+		want       Interval
+	}{
+		{5, 10, 0.95, Interval{0.2366, 0.7634}},
+		{0, 10, 0.95, Interval{0, 0.2775}},
+		{10, 10, 0.95, Interval{0.7225, 1}},
+		{1, 20, 0.95, Interval{0.0089, 0.2361}},
+		{81, 263, 0.95, Interval{0.2553, 0.3662}},
+		{500, 1000, 0.99, Interval{0.4594, 0.5406}},
+		{30, 40, 0.80, Interval{0.6536, 0.8267}},
+		{0, 0, 0.95, Interval{0, 1}},
+	}
+	for _, tt := range tests {
+		got := WilsonInterval(tt.wins, tt.n, tt.confidence)
+		if math.Abs(got.Lo-tt.want.Lo) > 5e-5 || math.Abs(got.Hi-tt.want.Hi) > 5e-5 {
+			t.Errorf("WilsonInterval(%d, %d, %g) = [%.4f, %.4f], want [%.4f, %.4f]",
+				tt.wins, tt.n, tt.confidence, got.Lo, got.Hi, tt.want.Lo, tt.want.Hi)
+		}
+	}
+}
+
+func TestBootstrapRatio(t *testing.T) {
+	tests := []struct {
+		name       string
+		num, den   []float64
+		confidence float64
+		want       Interval
+	}{
+		{"empty", nil, nil, 0.95, Interval{}},
+		// every cluster has the same ratio, so every resample does too
+		{"constant ratio", []float64{2, 4, 6}, []float64{4, 8, 12}, 0.95, Interval{0.5, 0.5}},
+		// two clusters resample to 0, 1/2 or 1 with chances 1/4, 1/2, 1/4
+		{"two clusters 95%", []float64{1, 0}, []float64{1, 1}, 0.95, Interval{0, 1}},
+		{"two clusters 40%", []float64{1, 0}, []float64{1, 1}, 0.40, Interval{0.5, 0.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BootstrapRatio(tt.num, tt.den, 20000, tt.confidence, rand.New(rand.NewSource(1)))
+			if math.Abs(got.Lo-tt.want.Lo) > 1e-9 || math.Abs(got.Hi-tt.want.Hi) > 1e-9 {
+				t.Errorf("got [%g, %g], want [%g, %g]", got.Lo, got.Hi, tt.want.Lo, tt.want.Hi)
+			}
+		})
+	}
+}
+
+func TestBootstrapRatioReproducible(t *testing.T) {
+	num := []float64{3, 7, 2, 9, 4}
+	den := []float64{5, 8, 6, 10, 5}
+	a := BootstrapRatio(num, den, 0, 0.9, rand.New(rand.NewSource(7)))
+	b := BootstrapRatio(num, den, 0, 0.9, rand.New(rand.NewSource(7)))
+	if a != b {
+		t.Errorf("same seed gave %v and %v", a, b)
+	}
+	ratio := 25.0 / 34
+	if a.Lo > ratio || a.Hi < ratio {
+		t.Errorf("interval %v misses the overall ratio %g", a, ratio)
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	tests := []struct {
+		xs   []float64
+		q    float64
+		want float64
+	}{
+		{[]float64{3, 1, 2}, 0, 1},
+		{[]float64{3, 1, 2}, 0.5, 2},
+		{[]float64{3, 1, 2}, 1, 3},
+		{[]float64{4, 0}, 0.25, 1},
+		{[]float64{10, 20, 30, 40}, 0.5, 25},
+	}
+	for _, tt := range tests {
+		if got := quantile(tt.xs, tt.q); got != tt.want {
+			t.Errorf("quantile(%v, %g) = %g, want %g", tt.xs, tt.q, got, tt.want)
+		}
+	}
+	if got := quantile(nil, 0.5); !math.IsNaN(got) {
+		t.Errorf("quantile of nothing = %g, want NaN", got)
+	}
+}
@@ -0,0 +1,131 @@
+package sim
+
+import "slices"
+
+// Crowd-control debuff types. Unlike stat and DoT debuffs they change what
+// the target may do rather than how hard it hits or how much it bleeds:
+//
+//   - stun: the target loses its turns
+//   - sleep: like stun, but any damage wakes the target up
+//   - silence: the target can't use abilities with a positive ManaCost
+//   - taunt: the target's single-target abilities aimed at enemies must go
+//     to whoever applied the taunt, while they are still standing
+//
+// Their Rounds count the target's own turns, not rounds, so a stun costs the
+// target a turn whether the caster is faster or slower than it; see
+// tickControl.
+const (
+	DebuffStun    = "stun"
+	DebuffSleep   = "sleep"
+	DebuffSilence = "silence"
+	DebuffTaunt   = "taunt"
+)
+
+// HasDebuff reports whether c is under at least one debuff of type stat.
+func (c *Character) HasDebuff(stat string) bool {
+	for _, db := range c.ActiveDebuffs {
+		if db.Stat == stat {
+			return true
+		}
+	}
+	return false
+}
+
+// Incapacitated returns the control effect that costs c its turn, stun or
+// sleep, or "" if c may act.
+func (c *Character) Incapacitated() string {
+	for _, stat := range []string{DebuffStun, DebuffSleep} {
+		if c.HasDebuff(stat) {
+			return stat
+		}
+	}
+	return ""
+}
+
+// CanUse reports whether c can use ab right now: it is Ready, c has the
+// mana, and c isn't silenced out of a mana-costing ability.
+func (c *Character) CanUse(ab *Ability) bool {
+	if c.Mana < ab.ManaCost || !c.Ready(ab) {
+		return false
+	}
+	return ab.ManaCost <= 0 || !c.HasDebuff(DebuffSilence)
+}
+
+// UsableAbilities returns the abilities c can use right now, in order. Every
+// decision function filters through it.
+func UsableAbilities(c *Character) []*Ability {
+	usable := make([]*Ability, 0, len(c.Abilities))
+	for _, ab := range c.Abilities {
+		if c.CanUse(ab) {
+			usable = append(usable, ab)
+		}
+	}
+	return usable
+}
+
+// Taunter returns the live character among opponents that has taunted c,
+// the most recent one if several have, or nil.
+func Taunter(c *Character, opponents []*Character) *Character {
+	for i := len(c.ActiveDebuffs) - 1; i >= 0; i-- {
+		db := c.ActiveDebuffs[i]
+		if db.Stat != DebuffTaunt {
+			continue
+		}
+		for _, o := range opponents {
+			if o.ID == db.AppliedBy && o.IsAlly != c.IsAlly && o.Health > 0 {
+				return o
+			}
+		}
+	}
+	return nil
+}
+
+// Tauntable reports whether a taunt restricts ab: it is a single-target
+// ability aimed at enemies.
+func Tauntable(ab *Ability) bool {
+	return ab.TargetType == "single" && ab.TargetSelectType == "enemy"
+}
+
+// TargetPool narrows pool, the candidates for ab, to the taunter if actor is
+// taunted and the taunt applies to ab.
+func TargetPool(actor *Character, ab *Ability, pool []*Character) []*Character {
+	if !Tauntable(ab) {
+		return pool
+	}
+	if t := Taunter(actor, pool); t != nil {
+		return []*Character{t}
+	}
+	return pool
+}
+
+// isControl reports whether stat is one of the crowd-control debuff types.
+func isControl(stat string) bool {
+	return slices.Contains(ControlDebuffTypes, stat)
+}
+
+// tickControl counts the turn c is starting against its control debuffs.
+// Those that have already lasted all their turns wear off first; the rest
+// hold for this turn.
+func (e *Engine) tickControl(c *Character) {
+	for i := len(c.ActiveDebuffs) - 1; i >= 0; i-- {
+		db := &c.ActiveDebuffs[i]
+		if !isControl(db.Stat) {
+			continue
+		}
+		db.RoundsApplied++
+		if db.RoundsApplied > db.TotalRounds {
+			e.emitFor(EventEffectExpired, nil, c, Event{Stat: db.Stat})
+			c.ActiveDebuffs = slices.Delete(c.ActiveDebuffs, i, i+1)
+		}
+	}
+}
+
+// wake ends every sleep on c; damage calls it.
+func (e *Engine) wake(c *Character) {
+	for i := len(c.ActiveDebuffs) - 1; i >= 0; i-- {
+		if c.ActiveDebuffs[i].Stat == DebuffSleep {
+			c.ActiveDebuffs = slices.Delete(c.ActiveDebuffs, i, i+1)
+			e.emitFor(EventEffectExpired, nil, c, Event{Stat: DebuffSleep})
+		}
+	}
+}
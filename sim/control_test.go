@@ -0,0 +1,117 @@
+package sim
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestControlCountsTargetTurns(t *testing.T) {
+	tests := []struct {
+		name        string
+		stat        string
+		rounds      int
+		casterSpeed float64
+		targetSpeed float64
+		atb         bool
+	}{
+		{"stun from a slower caster", DebuffStun, 1, 5, 10, false},
+		{"stun from a faster caster", DebuffStun, 1, 10, 5, false},
+		{"long stun from a slower caster", DebuffStun, 2, 5, 10, false},
+		{"stun on a target three times as fast", DebuffStun, 1, 5, 15, true},
+		{"sleep from a slower caster", DebuffSleep, 1, 5, 10, false},
+		{"silence from a slower caster", DebuffSilence, 2, 5, 10, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			control := &Ability{ID: "control", Type: "debuff", TargetType: "single", TargetSelectType: "enemy",
+				Debuff: &Debuff{Type: tt.stat, Rounds: tt.rounds, ApplicationChance: 100}}
+			focus := &Ability{ID: "focus", Type: "buff", TargetType: "single", TargetSelectType: "ally",
+				ManaCost: 1, Buff: &Buff{Type: "strength", Rounds: 1, ModifierPercent: 10}}
+
+			caster, target := fighter("a", true, tt.casterSpeed), fighter("e", false, tt.targetSpeed)
+			target.Mana, target.MaxMana = 1000, 1000
+			e := NewEngine([]*Character{caster}, []*Character{target}, 1)
+			if tt.atb {
+				e.UseATB()
+			}
+
+			// one entry per turn of the target: whether it got to act
+			var acted []bool
+			controlledAt := -1
+			e.Events = EventSinkFunc(func(ev Event) {
+				switch {
+				case ev.Type == EventDebuffApplied && ev.Stat == tt.stat:
+					controlledAt = len(acted)
+				case ev.Type == EventTurnStarted && ev.Actor == "e":
+					acted = append(acted, false)
+				case ev.Type == EventAbilityUsed && ev.Actor == "e":
+					acted[len(acted)-1] = true
+				}
+			})
+			focusing := slices.Repeat([]move{{focus, "e"}}, 1000)
+			playOut(e, scripted(map[string][]move{"a": {{control, "e"}}, "e": focusing}))
+
+			if controlledAt < 0 {
+				t.Fatal("the control debuff never landed")
+			}
+			for i, ok := range acted {
+				lost := i >= controlledAt && i < controlledAt+tt.rounds
+				if ok == lost {
+					t.Errorf("target turn %d: acted %v, want %v (control landed before turn %d)", i+1, ok, !lost, controlledAt+1)
+				}
+			}
+		})
+	}
+}
+
+func TestSleepBreaksOnDamage(t *testing.T) {
+	lullaby := &Ability{ID: "lullaby", Type: "debuff", TargetType: "single", TargetSelectType: "enemy",
+		Debuff: &Debuff{Type: DebuffSleep, Rounds: 3, ApplicationChance: 100}}
+	singer, hitter := fighter("a1", true, 10), fighter("a2", true, 8)
+	target := fighter("e", false, 5)
+	e := NewEngine([]*Character{singer, hitter}, []*Character{target}, 1)
+	var skipped int
+	e.Events = EventSinkFunc(func(ev Event) {
+		if ev.Type == EventTurnSkipped && ev.Actor == "e" && ev.Stat == DebuffSleep {
+			skipped++
+		}
+	})
+	// put to sleep, left alone for a turn, then woken by a hit
+	policy := scripted(map[string][]move{
+		"a1": {{lullaby, "e"}},
+		"a2": {{}, {}, {testBash, "e"}},
+	})
+	for e.TotalRounds < 5 && !e.GameOver {
+		e.Step(policy)
+	}
+	if skipped != 2 {
+		t.Errorf("slept through %d turns, want 2", skipped)
+	}
+	if target.HasDebuff(DebuffSleep) {
+		t.Error("still asleep after being hit")
+	}
+}
+
+func TestTauntRedirects(t *testing.T) {
+	provoke := &Ability{ID: "provoke", Type: "debuff", TargetType: "single", TargetSelectType: "enemy",
+		Debuff: &Debuff{Type: DebuffTaunt, Rounds: 1, ApplicationChance: 100}}
+	tank, squishy := fighter("tank", true, 10), fighter("squishy", true, 1)
+	enemy := fighter("e", false, 5)
+	e := NewEngine([]*Character{tank, squishy}, []*Character{enemy}, 1)
+	var hit []string
+	e.Events = EventSinkFunc(func(ev Event) {
+		if ev.Type == EventDamage && ev.Actor == "e" {
+			hit = append(hit, ev.Target)
+		}
+	})
+	policy := scripted(map[string][]move{
+		"tank": {{provoke, "e"}},
+		"e":    {{testBash, "squishy"}, {testBash, "squishy"}},
+	})
+	for e.TotalRounds < 2 && !e.GameOver {
+		e.Step(policy)
+	}
+	if want := []string{"tank", "squishy"}; !slices.Equal(hit, want) {
+		t.Errorf("enemy hit %v, want %v", hit, want)
+	}
+}
@@ -0,0 +1,72 @@
+package sim
+
+// SkipCharging is the Stat of the TurnSkipped event for a turn spent
+// winding up a charge ability.
+const SkipCharging = "charging"
+
+// CHARGE_DISCOUNT is how much UtilityDecision marks an ability down for each
+// turn it takes to charge, since the enemy gets to act in between.
+const CHARGE_DISCOUNT = 0.75
+
+// Charge is a charge ability c has committed to: mana paid and targets
+// chosen, with its effect TurnsLeft of c's turns away. Its hits crit with the
+// chance c had when it started charging, before paying.
+type Charge struct {
+	Ability    *Ability
+	Targets    []*Character
+	TurnsLeft  int
+	CritChance float64
+}
+
+// Ready reports whether ab is off cooldown for c and has uses left. Ready
+// only makes sense during one of c's turns, since cooldowns are counted in
+// them; a turn lost to stun or sleep still counts.
+func (c *Character) Ready(ab *Ability) bool {
+	if c.Turns <= ab.InitialCooldown || c.Turns < c.ReadyAt[ab.ID] {
+		return false
+	}
+	return ab.Uses <= 0 || c.Used[ab.ID] < ab.Uses
+}
+
+// commit records that c used ab this turn: one use spent and its cooldown
+// started.
+func (c *Character) commit(ab *Ability) {
+	if c.Used == nil {
+		c.Used = map[string]int{}
+	}
+	c.Used[ab.ID]++
+	if ab.Cooldown > 0 {
+		if c.ReadyAt == nil {
+			c.ReadyAt = map[string]int{}
+		}
+		c.ReadyAt[ab.ID] = c.Turns + ab.Cooldown + 1
+	}
+}
+
+// continueCharge spends actor's turn on its charge: one more turn of winding
+// up, or the payoff on the targets still standing.
+func (e *Engine) continueCharge(actor *Character) {
+	ch := actor.Charging
+	ch.TurnsLeft--
+	if ch.TurnsLeft > 0 {
+		e.emitFor(EventTurnSkipped, actor, nil, Event{Ability: ch.Ability.ID, Stat: SkipCharging})
+		e.advanceTurn()
+		return
+	}
+	actor.Charging = nil
+
+	targets := make([]*Character, 0, len(ch.Targets))
+	for _, t := range ch.Targets {
+		if t.Health > 0 {
+			targets = append(targets, t)
+		}
+	}
+	if len(targets) == 0 {
+		// everyone it was aimed at fell in the meantime
+		e.emitFor(EventTurnSkipped, actor, nil, Event{Ability: ch.Ability.ID})
+		e.advanceTurn()
+		return
+	}
+	e.emitFor(EventAbilityUsed, actor, nil, Event{Ability: ch.Ability.ID, Amount: ch.Ability.ManaCost, Charged: true})
+	e.resolve(actor, ch.Ability, targets, ch.CritChance)
+}
@@ -0,0 +1,121 @@
+package sim
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestReady(t *testing.T) {
+	tests := []struct {
+		name    string
+		ability Ability
+		used    []int // turns c used the ability on
+		turn    int
+		want    bool
+	}{
+		{"plain", Ability{}, []int{1, 2}, 3, true},
+		{"initial cooldown", Ability{InitialCooldown: 2}, nil, 2, false},
+		{"initial cooldown over", Ability{InitialCooldown: 2}, nil, 3, true},
+		{"on cooldown", Ability{Cooldown: 2}, []int{1}, 3, false},
+		{"off cooldown", Ability{Cooldown: 2}, []int{1}, 4, true},
+		{"uses left", Ability{Uses: 2}, []int{1}, 2, true},
+		{"used up", Ability{Uses: 2}, []int{1, 2}, 9, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ab := tt.ability
+			ab.ID = "ab"
+			c := &Character{}
+			for _, turn := range tt.used {
+				c.Turns = turn
+				c.commit(&ab)
+			}
+			c.Turns = tt.turn
+			if got := c.Ready(&ab); got != tt.want {
+				t.Errorf("Ready on turn %d = %v, want %v", tt.turn, got, tt.want)
+			}
+		})
+	}
+}
+
+// What a character did with each of its turns.
+const (
+	turnUsed    = "used"
+	turnCharged = "charge"
+	turnWound   = "wind"
+	turnSkipped = "skip"
+)
+
+func TestCooldownsAndCharges(t *testing.T) {
+	stun := &Ability{ID: "stun", Type: "debuff", TargetType: "single", TargetSelectType: "enemy",
+		Debuff: &Debuff{Type: DebuffStun, Rounds: 1, ApplicationChance: 100}}
+	tests := []struct {
+		name     string
+		ability  Ability
+		stunned  bool // the enemy stuns the actor after its first turn
+		want     []string
+		wantHits int
+	}{
+		{"cooldown", Ability{Cooldown: 2}, false,
+			[]string{turnUsed, turnSkipped, turnSkipped, turnUsed, turnSkipped, turnSkipped, turnUsed}, 3},
+		{"initial cooldown", Ability{InitialCooldown: 2}, false,
+			[]string{turnSkipped, turnSkipped, turnUsed, turnUsed}, 2},
+		{"uses", Ability{Uses: 2}, false,
+			[]string{turnUsed, turnUsed, turnSkipped, turnSkipped}, 2},
+		{"charge", Ability{ChargeTurns: 2}, false,
+			[]string{turnCharged, turnWound, turnUsed, turnCharged, turnWound, turnUsed}, 2},
+		{"stunned while charging", Ability{ChargeTurns: 1}, true,
+			[]string{turnCharged, turnSkipped, turnUsed, turnCharged, turnUsed}, 2},
+		{"charge on cooldown", Ability{ChargeTurns: 1, Cooldown: 2}, false,
+			[]string{turnCharged, turnUsed, turnSkipped, turnCharged, turnUsed}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ab := tt.ability
+			ab.ID, ab.Type, ab.TargetType, ab.TargetSelectType, ab.Power = "strike", "attack", "single", "enemy", 30
+
+			actor, enemy := fighter("a", true, 10), fighter("e", false, 5)
+			enemy.Health, enemy.MaxHealth = 10000, 10000
+			e := NewEngine([]*Character{actor}, []*Character{enemy}, 1)
+			var turns []string
+			hits := 0
+			e.Events = EventSinkFunc(func(ev Event) {
+				if ev.Type == EventDamage && ev.Actor == "a" {
+					hits++
+				}
+				if ev.Actor != "a" || len(turns) > len(tt.want) {
+					return
+				}
+				switch ev.Type {
+				case EventTurnStarted:
+					turns = append(turns, "")
+				case EventAbilityUsed:
+					turns[len(turns)-1] = turnUsed
+				case EventChargeStarted:
+					turns[len(turns)-1] = turnCharged
+				case EventTurnSkipped:
+					if ev.Stat == SkipCharging {
+						turns[len(turns)-1] = turnWound
+					} else {
+						turns[len(turns)-1] = turnSkipped
+					}
+				}
+			})
+			moves := map[string][]move{"a": slices.Repeat([]move{{&ab, "e"}}, len(tt.want))}
+			if tt.stunned {
+				moves["e"] = []move{{stun, "a"}}
+			}
+			policy := scripted(moves)
+			for len(turns) <= len(tt.want) && !e.GameOver {
+				e.Step(policy)
+			}
+
+			if got := turns[:len(tt.want)]; !slices.Equal(got, tt.want) {
+				t.Errorf("turns %v, want %v", got, tt.want)
+			}
+			if hits != tt.wantHits {
+				t.Errorf("%d hits, want %d", hits, tt.wantHits)
+			}
+		})
+	}
+}
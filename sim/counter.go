@@ -0,0 +1,142 @@
+package sim
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// CounterConfig describes a search for the ally teams that beat one enemy
+// team. Batch supplies levels, policies, seed, workers and confidence; its
+// Matchups are ignored and its Trials are the battles every candidate plays
+// in the first round. Batch.MaxTrials, if set, caps the battles any one
+// candidate plays in total.
+type CounterConfig struct {
+	Batch BatchConfig
+
+	Enemy      []string
+	Candidates [][]string // every team of len(Enemy) from the roster if nil
+
+	// Keep is how many teams the search narrows down to; 0 means 10.
+	Keep int
+}
+
+// CounterTeam is one candidate's record against the enemy, over every round
+// it took part in.
+type CounterTeam struct {
+	Team      []string `json:"team"`
+	Battles   int      `json:"battles"`
+	Wins      int      `json:"wins"`
+	WinRate   float64  `json:"win_rate"`
+	WinRateCI Interval `json:"win_rate_ci"`
+	Rounds    int      `json:"rounds"` // rounds survived; finalists have the most
+}
+
+// CounterResult is the outcome of FindCounters.
+type CounterResult struct {
+	Enemy   []string      `json:"enemy"`
+	Rounds  int           `json:"rounds"`
+	Battles int           `json:"battles"`
+	Teams   []CounterTeam `json:"teams"` // finalists first, then by win rate
+}
+
+// FindCounters searches the candidates for the ally teams with the best win
+// rate against cfg.Enemy by successive halving. Each round every surviving
+// candidate plays a fresh batch, twice as large as the last. Between rounds,
+// candidates whose interval lies wholly below that of the Keep-th best are
+// dropped, then the bottom half, until Keep remain.
+func FindCounters(cfg CounterConfig) (*CounterResult, error) {
+	if len(cfg.Enemy) == 0 {
+		return nil, fmt.Errorf("empty enemy team")
+	}
+	if cfg.Keep < 0 {
+		return nil, fmt.Errorf("keep must not be negative, got %d", cfg.Keep)
+	}
+	keep := cfg.Keep
+	if keep == 0 {
+		keep = 10
+	}
+	candidates := cfg.Candidates
+	if candidates == nil {
+		candidates = UniqueTeams(AllCharacterKeys(), len(cfg.Enemy))
+	}
+	conf := cfg.Batch.Confidence
+	if conf == 0 {
+		conf = DefaultConfidence
+	}
+
+	out := &CounterResult{Enemy: cfg.Enemy}
+	teams := make([]CounterTeam, len(candidates))
+	for i, c := range candidates {
+		teams[i] = CounterTeam{Team: c}
+	}
+	alive := make([]int, len(candidates))
+	for i := range alive {
+		alive[i] = i
+	}
+
+	trials := cfg.Batch.Trials
+	for round := 0; len(alive) > 0; round++ {
+		if cfg.Batch.MaxTrials > 0 && round > 0 && teams[alive[0]].Battles+trials > cfg.Batch.MaxTrials {
+			break
+		}
+		batch := cfg.Batch
+		batch.Trials = trials
+		batch.TargetWidth = 0
+		batch.Seed = BattleSeed(cfg.Batch.Seed, round, -1)
+		batch.Matchups = make([]Matchup, len(alive))
+		for i, t := range alive {
+			batch.Matchups[i] = Matchup{A: teams[t].Team, B: cfg.Enemy}
+		}
+		res, err := RunBatch(batch)
+		if err != nil {
+			return nil, err
+		}
+		out.Rounds++
+		out.Battles += res.Battles
+		for i, t := range alive {
+			ct := &teams[t]
+			ct.Battles += res.Matchups[i].Battles
+			ct.Wins += res.Matchups[i].AWins
+			ct.WinRate = ratio(float64(ct.Wins), ct.Battles)
+			ct.WinRateCI = WilsonInterval(ct.Wins, ct.Battles, conf)
+			ct.Rounds++
+		}
+		if len(alive) <= keep {
+			break
+		}
+		alive = pruneCounters(teams, alive, keep)
+		trials *= 2
+	}
+
+	slices.SortStableFunc(teams, func(a, b CounterTeam) int {
+		if c := cmp.Compare(b.Rounds, a.Rounds); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.WinRate, a.WinRate)
+	})
+	out.Teams = teams
+	return out, nil
+}
+
+// pruneCounters returns the candidates of alive worth another round: those
+// whose interval reaches the lower bound of the keep-th best, cut to the
+// better half but never below keep.
+func pruneCounters(teams []CounterTeam, alive []int, keep int) []int {
+	ranked := slices.Clone(alive)
+	slices.SortStableFunc(ranked, func(a, b int) int {
+		return cmp.Compare(teams[b].WinRate, teams[a].WinRate)
+	})
+	bar := teams[ranked[keep-1]].WinRateCI.Lo
+	var next []int
+	for _, t := range ranked {
+		if teams[t].WinRateCI.Hi >= bar {
+			next = append(next, t)
+		}
+	}
+	half := max(keep, (len(ranked)+1)/2)
+	if len(next) > half {
+		next = next[:half]
+	}
+	return next
+}
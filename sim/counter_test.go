@@ -0,0 +1,46 @@
+package sim
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPruneCounters(t *testing.T) {
+	// candidate with a win rate and interval
+	team := func(rate, lo, hi float64) CounterTeam {
+		return CounterTeam{WinRate: rate, WinRateCI: Interval{lo, hi}}
+	}
+	tests := []struct {
+		name  string
+		teams []CounterTeam
+		keep  int
+		want  []int
+	}{
+		// the bar is 0.6, the 2nd best's lower bound; 3 reaches it exactly
+		// and 1 falls short
+		{"below the bar", []CounterTeam{
+			team(0.9, 0.8, 1), team(0.3, 0.2, 0.5), team(0.7, 0.6, 0.8),
+			team(0.5, 0.4, 0.6), team(0.2, 0.1, 0.59), team(0.4, 0.3, 0.65),
+		}, 2, []int{0, 2, 3}},
+		// everyone reaches the bar, and more than half survive
+		{"overlapping", []CounterTeam{
+			team(0.5, 0.3, 0.7), team(0.6, 0.4, 0.8), team(0.4, 0.2, 0.6),
+			team(0.55, 0.35, 0.75), team(0.45, 0.25, 0.65),
+		}, 1, []int{1, 3, 0}},
+		// halving would leave 2, but never fewer than keep survive
+		{"keep over half", []CounterTeam{
+			team(0.5, 0.3, 0.7), team(0.6, 0.4, 0.8), team(0.4, 0.2, 0.6), team(0.55, 0.35, 0.75),
+		}, 3, []int{1, 3, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alive := make([]int, len(tt.teams))
+			for i := range alive {
+				alive[i] = i
+			}
+			if got := pruneCounters(tt.teams, alive, tt.keep); !slices.Equal(got, tt.want) {
+				t.Errorf("kept %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
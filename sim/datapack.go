@@ -0,0 +1,220 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DataPack is a complete set of balance data: character templates, abilities
+// and the element chart. The built-in maps are the default pack.
+type DataPack struct {
+	Characters map[string]CharacterTemplate `json:"characters"`
+	Abilities  map[string]*Ability          `json:"abilities"`
+	Elements   map[Element]ElementInfo      `json:"elements"`
+}
+
+// Data pack file names, without extension. Each may be .json, .yaml or .yml.
+const (
+	CharactersFile = "characters"
+	AbilitiesFile  = "abilities"
+	ElementsFile   = "elements"
+)
+
+var packExtensions = []string{".json", ".yaml", ".yml"}
+
+// builtinPack is a private copy of the compiled-in data, taken before anything
+// can be installed over it.
+var builtinPack = (&DataPack{
+	Characters: CharacterTemplates,
+	Abilities:  AbilityDict,
+	Elements:   ElementChart,
+}).Clone()
+
+// DefaultPack returns a fresh copy of the built-in data.
+func DefaultPack() *DataPack {
+	return builtinPack.Clone()
+}
+
+// CurrentPack returns the data currently installed in the package globals.
+// The maps are shared, not copied.
+func CurrentPack() *DataPack {
+	return &DataPack{
+		Characters: CharacterTemplates,
+		Abilities:  AbilityDict,
+		Elements:   ElementChart,
+	}
+}
+
+// Install makes p the data used by MakeTeam and the engine. It must not be
+// called while battles are running.
+func (p *DataPack) Install() {
+	CharacterTemplates = p.Characters
+	AbilityDict = p.Abilities
+	ElementChart = p.Elements
+}
+
+// Clone returns a deep copy of p, so the copy can be tweaked freely.
+func (p *DataPack) Clone() *DataPack {
+	out := &DataPack{
+		Characters: make(map[string]CharacterTemplate, len(p.Characters)),
+		Abilities:  make(map[string]*Ability, len(p.Abilities)),
+		Elements:   make(map[Element]ElementInfo, len(p.Elements)),
+	}
+	for k, t := range p.Characters {
+		t.Elements = slices.Clone(t.Elements)
+		t.AbilityTemplates = slices.Clone(t.AbilityTemplates)
+		out.Characters[k] = t
+	}
+	for k, ab := range p.Abilities {
+		if ab == nil {
+			out.Abilities[k] = nil
+			continue
+		}
+		c := *ab
+		if ab.Buff != nil {
+			b := *ab.Buff
+			c.Buff = &b
+		}
+		if ab.Debuff != nil {
+			d := *ab.Debuff
+			c.Debuff = &d
+		}
+		out.Abilities[k] = &c
+	}
+	for k, info := range p.Elements {
+		out.Elements[k] = ElementInfo{
+			Strengths:  slices.Clone(info.Strengths),
+			Weaknesses: slices.Clone(info.Weaknesses),
+		}
+	}
+	return out
+}
+
+// LoadDataPack reads a data pack from dir with ReadDataPack and validates it.
+// A pack with problems is rejected with a ValidationErrors listing them all.
+func LoadDataPack(dir string) (*DataPack, error) {
+	p, err := ReadDataPack(dir)
+	if err != nil {
+		return nil, err
+	}
+	if errs := p.Validate(); errs != nil {
+		return nil, errs
+	}
+	return p, nil
+}
+
+// ReadDataPack reads characters, abilities and elements files from dir without
+// validating them. Any file that is missing falls back to the built-in data
+// for that part.
+func ReadDataPack(dir string) (*DataPack, error) {
+	if info, err := os.Stat(dir); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("%s: not a directory", dir)
+	}
+	p := DefaultPack()
+	if err := loadPackFile(dir, CharactersFile, &p.Characters); err != nil {
+		return nil, err
+	}
+	if err := loadPackFile(dir, AbilitiesFile, &p.Abilities); err != nil {
+		return nil, err
+	}
+	if err := loadPackFile(dir, ElementsFile, &p.Elements); err != nil {
+		return nil, err
+	}
+
+	// the map key is the ability's identity; fill in IDs left out of the file
+	for key, ab := range p.Abilities {
+		if ab != nil && ab.ID == "" {
+			ab.ID = key
+		}
+	}
+	return p, nil
+}
+
+// SaveDataPack writes p to dir as three indented JSON files, a starting point
+// for a custom pack.
+func SaveDataPack(dir string, p *DataPack) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	files := []struct {
+		name string
+		v    any
+	}{
+		{CharactersFile, p.Characters},
+		{AbilitiesFile, p.Abilities},
+		{ElementsFile, p.Elements},
+	}
+	for _, f := range files {
+		data, err := json.MarshalIndent(f.v, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, f.name+".json"), append(data, '\n'), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadPackFile decodes dir/name.{json,yaml,yml} into out, which keeps its
+// current value if no such file exists. Unknown fields are rejected so a
+// misspelled key doesn't silently fall back to zero, and so is a directory
+// with the same part in two formats, since it is unclear which one is meant.
+func loadPackFile[T any](dir, name string, out *T) error {
+	var found []string
+	for _, ext := range packExtensions {
+		path := filepath.Join(dir, name+ext)
+		if _, err := os.Stat(path); err == nil {
+			found = append(found, path)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil
+	case 1:
+	default:
+		names := make([]string, len(found))
+		for i, path := range found {
+			names[i] = filepath.Base(path)
+		}
+		return fmt.Errorf("%s: ambiguous, found both %s", dir, strings.Join(names, " and "))
+	}
+
+	path := found[0]
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if filepath.Ext(path) != ".json" {
+		// YAML goes through JSON so both formats share the same field names
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	// decode into a fresh value so the file replaces the default instead of merging
+	var fresh T
+	if err := dec.Decode(&fresh); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	*out = fresh
+	return nil
+}
@@ -0,0 +1,120 @@
+package sim
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// writePackFile writes v to dir/name in the format its extension names.
+func writePackFile(t *testing.T, dir, name string, v any) {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Ext(name) != ".json" {
+		// through a generic value, so the YAML keys are the JSON field names
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatal(err)
+		}
+		if data, err = yaml.Marshal(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadDataPackFormats(t *testing.T) {
+	hero := DefaultPack().Characters["mycera"]
+	hero.BaseHealth = 123
+	for _, ext := range packExtensions {
+		t.Run(ext, func(t *testing.T) {
+			dir := t.TempDir()
+			writePackFile(t, dir, CharactersFile+ext, map[string]CharacterTemplate{"hero": hero})
+
+			p, err := ReadDataPack(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]CharacterTemplate{"hero": hero}
+			if !reflect.DeepEqual(p.Characters, want) {
+				t.Errorf("characters %v, want only hero", p.Characters)
+			}
+			// the files left out come from the built-in data
+			if def := DefaultPack(); !reflect.DeepEqual(p.Abilities, def.Abilities) || !reflect.DeepEqual(p.Elements, def.Elements) {
+				t.Error("missing files didn't fall back to the built-in data")
+			}
+		})
+	}
+}
+
+func TestReadDataPackErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string // substring of the error
+	}{
+		{"misspelled json key", map[string]string{"characters.json": `{"hero": {"base_helth": 10}}`}, "base_helth"},
+		{"misspelled yaml key", map[string]string{"abilities.yaml": "zap:\n  powr: 3\n"}, "powr"},
+		{"bad yaml", map[string]string{"elements.yml": "fire: [\n"}, "elements.yml"},
+		{"json and yaml", map[string]string{"characters.json": `{}`, "characters.yaml": "{}\n"}, "ambiguous"},
+		{"yaml and yml", map[string]string{"abilities.yaml": "{}\n", "abilities.yml": "{}\n"}, "ambiguous"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			_, err := ReadDataPack(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataPackRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	if err := SaveDataPack(dir, DefaultPack()); err != nil {
+		t.Fatal(err)
+	}
+	p, err := LoadDataPack(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p, DefaultPack()) {
+		t.Error("saved and loaded pack differs from the built-in data")
+	}
+}
+
+func TestCloneIsDeep(t *testing.T) {
+	want := DefaultPack()
+	c := DefaultPack()
+	c.Characters["mycera"].Elements[0] = "plasma"
+	c.Characters["mycera"].AbilityTemplates[0].MinLv = 99
+	c.Abilities["bash"].Power = 1
+	c.Abilities["barkskin"].Buff.Rounds = 99
+	c.Abilities["scorch"].Debuff.DamagePercent = 99
+	c.Elements[Fire].Strengths[0] = "plasma"
+
+	if got := DefaultPack(); !reflect.DeepEqual(got, want) {
+		t.Error("changing a clone changed the built-in data")
+	}
+	again := c.Clone()
+	again.Abilities["bash"].Power = 2
+	if c.Abilities["bash"].Power != 1 {
+		t.Error("changing a clone of a clone changed the original")
+	}
+}
@@ -0,0 +1,129 @@
+package sim
+
+import "math"
+
+// Histogram counts non-negative integer observations; Counts[v] is how many
+// times v was seen.
+type Histogram struct {
+	Counts []int `json:"counts"`
+	N      int   `json:"n"`
+	Sum    int   `json:"sum"`
+}
+
+// Add records one observation of v. Negative values count as 0.
+func (h *Histogram) Add(v int) {
+	v = max(v, 0)
+	for len(h.Counts) <= v {
+		h.Counts = append(h.Counts, 0)
+	}
+	h.Counts[v]++
+	h.N++
+	h.Sum += v
+}
+
+func (h *Histogram) merge(o *Histogram) {
+	for len(h.Counts) < len(o.Counts) {
+		h.Counts = append(h.Counts, 0)
+	}
+	for v, c := range o.Counts {
+		h.Counts[v] += c
+	}
+	h.N += o.N
+	h.Sum += o.Sum
+}
+
+// Mean is the average observation, or 0 if there are none.
+func (h *Histogram) Mean() float64 {
+	return ratio(float64(h.Sum), h.N)
+}
+
+// MeanInterval is a normal confidence interval for the mean, treating every
+// observation as independent. With fewer than two observations there is no
+// spread to go on and the interval is the mean alone.
+func (h *Histogram) MeanInterval(confidence float64) Interval {
+	mean := h.Mean()
+	if h.N < 2 {
+		return Interval{mean, mean}
+	}
+	var ss float64
+	for v, c := range h.Counts {
+		d := float64(v) - mean
+		ss += float64(c) * d * d
+	}
+	half := zScore(confidence) * math.Sqrt(ss/float64(h.N-1)/float64(h.N))
+	return Interval{mean - half, mean + half}
+}
+
+// Percentile returns the smallest value at or below which at least a
+// fraction q of the observations fall, or 0 if there are none.
+func (h *Histogram) Percentile(q float64) int {
+	if h.N == 0 {
+		return 0
+	}
+	need := q * float64(h.N)
+	seen := 0
+	for v, c := range h.Counts {
+		seen += c
+		if c > 0 && float64(seen) >= need {
+			return v
+		}
+	}
+	return len(h.Counts) - 1
+}
+
+// Distributions are the shapes behind a batch's averages.
+type Distributions struct {
+	Rounds    Histogram `json:"rounds"`
+	Turns     Histogram `json:"turns"`
+	FirstDown Histogram `json:"first_down"` // turn the first character fell, in battles where one did
+	WinnerHP  Histogram `json:"winner_hp"`  // percent of the winning side's max health left at the end
+	TimedOut  int       `json:"timed_out"`  // battles stopped at MAX_ROUNDS
+}
+
+func (d *Distributions) merge(o *Distributions) {
+	d.Rounds.merge(&o.Rounds)
+	d.Turns.merge(&o.Turns)
+	d.FirstDown.merge(&o.FirstDown)
+	d.WinnerHP.merge(&o.WinnerHP)
+	d.TimedOut += o.TimedOut
+}
+
+// battleWatch follows one battle step by step for Distributions.
+type battleWatch struct {
+	firstDown int // 0 until someone falls
+}
+
+// observe is called after every Step.
+func (w *battleWatch) observe(e *Engine) {
+	if w.firstDown > 0 {
+		return
+	}
+	for _, c := range e.Characters {
+		if c.Health <= 0 {
+			w.firstDown = max(e.TotalTurns, 1)
+			return
+		}
+	}
+}
+
+// finish adds the finished battle to d.
+func (w *battleWatch) finish(e *Engine, d *Distributions) {
+	d.Rounds.Add(e.TotalRounds)
+	d.Turns.Add(e.TotalTurns)
+	if w.firstDown > 0 {
+		d.FirstDown.Add(w.firstDown)
+	}
+	var hp, maxHP float64
+	for _, c := range e.Characters {
+		if c.IsAlly == e.PlayerWon {
+			hp += c.Health
+			maxHP += c.MaxHealth
+		}
+	}
+	if maxHP > 0 {
+		d.WinnerHP.Add(int(100*hp/maxHP + 0.5))
+	}
+	if e.TimedOut {
+		d.TimedOut++
+	}
+}
@@ -0,0 +1,38 @@
+package sim
+
+import "testing"
+
+func TestHistogramPercentile(t *testing.T) {
+	// 1, 2, 2, 3, 3, 3, 4, 4, 4, 4
+	var h Histogram
+	for v := 1; v <= 4; v++ {
+		for range v {
+			h.Add(v)
+		}
+	}
+	tests := []struct {
+		q    float64
+		want int
+	}{
+		{0, 1},
+		{0.1, 1},
+		{0.25, 2},
+		{0.3, 2},
+		{0.5, 3},
+		{0.6, 3},
+		{0.75, 4},
+		{1, 4},
+	}
+	for _, tt := range tests {
+		if got := h.Percentile(tt.q); got != tt.want {
+			t.Errorf("Percentile(%g) = %d, want %d", tt.q, got, tt.want)
+		}
+	}
+	if m := h.Mean(); m != 3 {
+		t.Errorf("Mean = %g, want 3", m)
+	}
+	var empty Histogram
+	if got := empty.Percentile(0.5); got != 0 {
+		t.Errorf("empty Percentile(0.5) = %d, want 0", got)
+	}
+}
@@ -16,14 +16,14 @@ const (
     Electric Element = "electric"
 )
 
-// elementInfo holds strengths (i.e. resistances) and weaknesses.
-type elementInfo struct {
-    Strengths  []Element // elements this one resists/is strong against
-    Weaknesses []Element // elements this one is weak to
+// ElementInfo holds strengths (i.e. resistances) and weaknesses.
+type ElementInfo struct {
+    Strengths  []Element `json:"strengths"`  // elements this one resists/is strong against
+    Weaknesses []Element `json:"weaknesses"` // elements this one is weak to
 }
 
 // ElementChart mirrors your JS elementWeaknessMap.
-var ElementChart = map[Element]elementInfo{
+var ElementChart = map[Element]ElementInfo{
     Air: {
         Strengths:  []Element{Wild},
         Weaknesses: []Element{Earth, Electric},
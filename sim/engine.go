@@ -5,20 +5,36 @@ import (
 	"math"
 	"math/rand"
 	"slices"
-	"sort"
 )
 
 type Engine struct {
 	Characters  []*Character
-	TurnOrder   []int // indices into Characters
+	TurnOrder   []int // indices into Characters, this round's turns in order
 	Current     int   // index into TurnOrder
+	ATB         bool  // initiative timeline instead of one turn each per round; see UseATB
 	PlayerWon   bool
 	GameOver    bool
+	TimedOut    bool           // ended at MAX_ROUNDS with both sides still standing
 	LastImpacts []ImpactRecord // last round’s impacts, for stats
 	TotalRounds int
 	TotalTurns  int
+
+	Seed         int64      // seed the engine was built from, for replays
+	Rand         *rand.Rand // combat rolls: evasion, debuff chance, tiebreaks
+	DecisionRand *rand.Rand // handed to decision functions, kept separate from combat rolls
+
+	Events EventSink // optional; receives every battle event
+
+	seeded     bool      // built by NewEngine, so Seed can restart it
+	tiebreak   []int64   // per character, drawn once from the seed; see planRound
+	initiative []float64 // per character, ATB mode only
 }
 
+// DecisionFunc picks an ability and its targets for actor. rng is the engine's
+// decision stream; implementations must draw all randomness from it.
+// A DecisionFunc is also a stateless Policy.
+type DecisionFunc func(actor *Character, allies, enemies []*Character, rng *rand.Rand) (*Ability, []*Character)
+
 type ImpactRecord struct {
 	ActorID  string  // who caused it
 	TargetID string  // who received it
@@ -28,45 +44,79 @@ type ImpactRecord struct {
 
 const ELEMENTAL_EFFECTIVENESS_MODIFIER = 1.5
 
-// NewEngine constructs a fresh engine from two teams.
-func NewEngine(allies, enemies []*Character) *Engine {
+// MAX_ROUNDS ends a battle that is still going. The side with anyone left
+// standing is then declared the winner, allies first.
+const MAX_ROUNDS = 100
+
+// NewEngine constructs a fresh engine from two teams, seeding every roll
+// from seed so the battle can be replayed from (seed, teams, level).
+func NewEngine(allies, enemies []*Character, seed int64) *Engine {
+	e := NewEngineWithSource(allies, enemies, rand.NewSource(seed))
+	e.Seed = seed
+	e.seeded = true
+	return e
+}
+
+// NewEngineWithSource constructs a fresh engine drawing all randomness from src.
+// The decision stream is seeded from the first value of src. A Source can't
+// be rewound, so Seed is left at 0 and the engine can't be Reset.
+func NewEngineWithSource(allies, enemies []*Character, src rand.Source) *Engine {
 	chars := append(allies, enemies...)
 	n := len(chars)
+	rng := rand.New(src)
+	decisionRng := rand.New(rand.NewSource(rng.Int63()))
 
-	// create a slice of indices [0,1,2...n-1]
-	order := make([]int, n)
-	for i := range order {
-		order[i] = i
+	// a random tiebreak per character for equal speeds
+	tiebreak := make([]int64, n)
+	for i := range tiebreak {
+		tiebreak[i] = rng.Int63()
 	}
 
-	// sort descending by chars[index].Speed, tiebreaker random
-	sort.Slice(order, func(i, j int) bool {
-		ci, cj := chars[order[i]], chars[order[j]]
-		if ci.Speed != cj.Speed {
-			return ci.Speed > cj.Speed
-		}
-		// same Speed → flip a coin
-		return rand.Intn(2) == 0
-	})
-
-	return &Engine{
-		Characters:  chars,
-		TurnOrder:   order,
-		GameOver:    false,
-		PlayerWon:   false,
-		Current:     0,
-		TotalRounds: 0,
-		TotalTurns:  0,
+	e := &Engine{
+		Characters:   chars,
+		GameOver:     false,
+		PlayerWon:    false,
+		Current:      0,
+		TotalRounds:  0,
+		TotalTurns:   0,
+		Rand:         rng,
+		DecisionRand: decisionRng,
+		tiebreak:     tiebreak,
 	}
+	e.planRound()
+	return e
 }
 
-// Reset brings the engine back to round 1 with fresh stats.
-func (e *Engine) Reset(allies, enemies []*Character) {
-	*e = *NewEngine(allies, enemies)
+// Reset brings the engine back to round 1 with fresh stats, reusing its seed,
+// turn mode and event sink. It fails on an engine from NewEngineWithSource,
+// which has no seed to start again from, and on a side with nobody on it;
+// the engine is left as it was.
+func (e *Engine) Reset(allies, enemies []*Character) error {
+	if !e.seeded {
+		return fmt.Errorf("reset of an engine built from a rand.Source; use NewEngine")
+	}
+	for _, side := range []struct {
+		name string
+		team []*Character
+	}{{"ally", allies}, {"enemy", enemies}} {
+		if len(side.team) == 0 {
+			return fmt.Errorf("empty %s team", side.name)
+		}
+		if slices.Contains(side.team, nil) {
+			return fmt.Errorf("nil character in the %s team", side.name)
+		}
+	}
+	sink, atb := e.Events, e.ATB
+	*e = *NewEngine(allies, enemies, e.Seed)
+	e.Events = sink
+	if atb {
+		e.UseATB()
+	}
+	return nil
 }
 
 // one turn’s logic: choose ability & target, apply effects
-func (e *Engine) Step(decisionFn func(actor *Character, allies, enemies []*Character) (*Ability, []*Character)) {
+func (e *Engine) Step(policy Policy) {
 	e.LastImpacts = e.LastImpacts[:0] // reset slice, reuse capacity
 	e.TotalTurns++
 	e.checkEnd()
@@ -80,6 +130,21 @@ func (e *Engine) Step(decisionFn func(actor *Character, allies, enemies []*Chara
 		return
 	}
 
+	e.emitFor(EventTurnStarted, actor, nil, Event{})
+	actor.Turns++
+	e.tickControl(actor)
+
+	// a lost turn doesn't wind a charge up any further
+	if cc := actor.Incapacitated(); cc != "" {
+		e.emitFor(EventTurnSkipped, actor, nil, Event{Stat: cc})
+		e.advanceTurn()
+		return
+	}
+	if actor.Charging != nil {
+		e.continueCharge(actor)
+		return
+	}
+
 	// Partition alive allies and enemies
 	var allies, enemies []*Character
 	for _, c := range e.Characters {
@@ -94,20 +159,57 @@ func (e *Engine) Step(decisionFn func(actor *Character, allies, enemies []*Chara
 	}
 
 	// Decision: pick ability AND targets
-	ability, targets := decisionFn(actor, allies, enemies)
-	if ability == nil || len(targets) == 0 {
+	ability, targets := policy.Decide(actor, allies, enemies, e.DecisionRand)
+	if ability == nil || len(targets) == 0 || !actor.CanUse(ability) {
 		// no valid action—just skip turn
-		fmt.Printf("DEBUG: %s has no valid action\n", actor.ID)
+		e.emitFor(EventTurnSkipped, actor, nil, Event{})
+		e.advanceTurn()
+		return
+	}
+	// a taunted actor's single-target attack goes to the taunter, whatever
+	// the policy picked
+	if Tauntable(ability) {
+		opponents := enemies
+		if !actor.IsAlly {
+			opponents = allies
+		}
+		if t := Taunter(actor, opponents); t != nil {
+			targets = []*Character{t}
+		}
+	}
+
+	actor.commit(ability)
+	// crits go by the mana the policy saw when it chose, before paying
+	critChance := CritChance(actor)
+	if ability.ChargeTurns > 0 {
+		// pay now, land ChargeTurns turns from now
+		e.emitFor(EventChargeStarted, actor, nil, Event{Ability: ability.ID, Rounds: ability.ChargeTurns})
+		e.payMana(actor, ability)
+		actor.Charging = &Charge{Ability: ability, Targets: targets, TurnsLeft: ability.ChargeTurns, CritChance: critChance}
 		e.advanceTurn()
 		return
 	}
 
-	// Pay mana
+	e.emitFor(EventAbilityUsed, actor, nil, Event{Ability: ability.ID, Amount: ability.ManaCost})
+	e.payMana(actor, ability)
+	e.resolve(actor, ability, targets, critChance)
+}
+
+// payMana takes ability's ManaCost from actor, or gives it back if negative.
+func (e *Engine) payMana(actor *Character, ability *Ability) {
+	manaBefore := actor.Mana
 	actor.Mana = clamp(actor.Mana-ability.ManaCost, 0, actor.MaxMana)
+	if actor.Mana != manaBefore {
+		e.emitFor(EventManaChanged, actor, nil, Event{Ability: ability.ID, Amount: actor.Mana - manaBefore})
+	}
+}
 
+// resolve applies ability to every target, each damaging hit a crit with
+// chance critChance, and ends actor's turn.
+func (e *Engine) resolve(actor *Character, ability *Ability, targets []*Character, critChance float64) {
 	// apply effects
 	for _, tgt := range targets {
-		delta := e.applyAbilityToTarget(actor, tgt, ability)
+		delta := e.applyAbilityToTarget(actor, tgt, ability, critChance)
 		// fmt.Printf("DEBUG: %s → %s = %0.1f\n", actor.ID, tgt.ID, delta)
 		e.LastImpacts = append(e.LastImpacts, ImpactRecord{
 			ActorID:  actor.ID,
@@ -124,13 +226,27 @@ func (e *Engine) Step(decisionFn func(actor *Character, allies, enemies []*Chara
 	}
 }
 
+// advanceTurn moves Current to the next character still standing, ending
+// the round and planning the next one when it runs out.
 func (e *Engine) advanceTurn() {
-	prev := e.Current
-	e.Current = (e.Current + 1) % len(e.TurnOrder)
-	if prev > e.Current {
-		// just wrapped a full round
-		e.HandleNewRoundEffects()
-		e.TotalRounds++
+	e.Current++
+	for {
+		if e.Current >= len(e.TurnOrder) {
+			e.HandleNewRoundEffects()
+			e.emit(Event{Type: EventRoundEnded})
+			e.TotalRounds++
+			// DoT may have settled it, or the round limit
+			if e.checkEnd(); e.GameOver {
+				return
+			}
+			e.planRound()
+			e.Current = 0
+			continue
+		}
+		if e.Characters[e.TurnOrder[e.Current]].Health > 0 {
+			return
+		}
+		e.Current++
 	}
 }
 
@@ -141,9 +257,14 @@ func (e *Engine) checkEnd() {
 			alives[c.IsAlly] = true
 		}
 	}
-	if !alives[true] || !alives[false] || e.TotalRounds >= 100 {
+	if !alives[true] || !alives[false] || e.TotalRounds >= MAX_ROUNDS {
+		wasOver := e.GameOver
 		e.GameOver = true
 		e.PlayerWon = alives[true]
+		e.TimedOut = alives[true] && alives[false]
+		if !wasOver {
+			e.emit(Event{Type: EventBattleEnded, PlayerWon: e.PlayerWon, TimedOut: e.TimedOut})
+		}
 	}
 }
 
@@ -157,11 +278,13 @@ func (e *Engine) HandleNewRoundEffects() {
 
 		// 1) Deal DoT from any debuffs that have a DamagePercent > 0
 		totalDot := 0.0
+		var killer *DebuffInstance // the tick that took c's last health
+		elems := c.CurrentElements()
 		for i := range c.ActiveDebuffs {
 			db := &c.ActiveDebuffs[i]
 			if db.DamagePercent > 0 {
 				elementalMod := 1.0
-				for _, elem := range c.Elements {
+				for _, elem := range elems {
 					for _, w := range GetWeaknesses(elem) {
 						if w == db.Element {
 							elementalMod *= ELEMENTAL_EFFECTIVENESS_MODIFIER
@@ -175,6 +298,20 @@ func (e *Engine) HandleNewRoundEffects() {
 				}
 				dot := math.Ceil(c.MaxHealth * (db.DamagePercent / 100) * elementalMod)
 				totalDot += dot
+				if killer == nil && totalDot >= c.Health {
+					killer = db
+				}
+				e.emit(Event{
+					Type:         EventDoTTick,
+					Actor:        db.AppliedBy,
+					Target:       c.ID,
+					TargetIsAlly: c.IsAlly,
+					ActorIsAlly:  db.AppliedByAlly,
+					Ability:      db.AbilityID,
+					Amount:       dot,
+					Stat:         db.Stat,
+					Element:      db.Element,
+				})
 
 				e.LastImpacts = append(e.LastImpacts, ImpactRecord{
 					ActorID:  db.AppliedBy,
@@ -186,6 +323,17 @@ func (e *Engine) HandleNewRoundEffects() {
 		}
 		if totalDot > 0 {
 			c.Health = math.Max(0, c.Health-totalDot)
+			e.wake(c)
+			if c.Health <= 0 {
+				e.emit(Event{
+					Type:         EventCharacterDown,
+					Actor:        killer.AppliedBy,
+					ActorIsAlly:  killer.AppliedByAlly,
+					Target:       c.ID,
+					TargetIsAlly: c.IsAlly,
+					Ability:      killer.AbilityID,
+				})
+			}
 		}
 
 		// 2) Expire buffs (they only modified damage when you cast; no stat rollback needed)
@@ -194,6 +342,7 @@ func (e *Engine) HandleNewRoundEffects() {
 			b.RoundsApplied++
 			if b.RoundsApplied >= b.TotalRounds {
 				// just remove it
+				e.emitFor(EventEffectExpired, nil, c, Event{Stat: b.Stat})
 				c.ActiveBuffs = slices.Delete(c.ActiveBuffs, i, i+1)
 			}
 		}
@@ -201,9 +350,18 @@ func (e *Engine) HandleNewRoundEffects() {
 		// 3) Expire debuffs (non‐DoT side effects—elements, stat‐mods—are only used at resolution)
 		for i := len(c.ActiveDebuffs) - 1; i >= 0; i-- {
 			db := &c.ActiveDebuffs[i]
+			if isControl(db.Stat) {
+				// counted in c's own turns instead; see tickControl
+				continue
+			}
 			db.RoundsApplied++
 			if db.RoundsApplied >= db.TotalRounds {
+				e.emitFor(EventEffectExpired, nil, c, Event{Stat: db.Stat, Element: db.ElementToApply})
+				lent := db.ElementToApply
 				c.ActiveDebuffs = slices.Delete(c.ActiveDebuffs, i, i+1)
+				if lent != "" && !slices.Contains(c.CurrentElements(), lent) {
+					e.emitFor(EventElementLost, nil, c, Event{Element: lent})
+				}
 			}
 		}
 	}
@@ -217,20 +375,24 @@ func (e *Engine) applyAbilityToTarget(
 	source *Character,
 	target *Character,
 	ability *Ability,
+	critChance float64,
 ) float64 {
 	// 1) calculate base impact: damage or heal
 	impact := 0.0
+	crit := false
 	switch ability.Type {
 	case "attack", "debuff":
-		if rand.Float64() < EffectiveEvasion(target) {
+		if e.Rand.Float64() < EffectiveEvasion(target) {
+			e.emitFor(EventMissed, source, target, Event{Ability: ability.ID})
 			return 0 // missed entirely
 		}
 		if target.Shields > 0 {
 			target.Shields -= 1
+			e.emitFor(EventShieldAbsorbed, source, target, Event{Ability: ability.ID})
 			return 0
 		}
 		elementalMod := 1.0
-		for _, elem := range target.Elements {
+		for _, elem := range target.CurrentElements() {
 			for _, w := range GetWeaknesses(elem) {
 				if w == ability.Element {
 					elementalMod *= ELEMENTAL_EFFECTIVENESS_MODIFIER
@@ -254,6 +416,10 @@ func (e *Engine) applyAbilityToTarget(
 			aoeMod = 0.67
 		}
 		damage := base * strMod * defMod * elementalMod * aoeMod
+		if damage > 0 && e.Rand.Float64() < critChance {
+			crit = true
+			damage *= CRIT_MULTIPLIER
+		}
 		impact = math.Ceil(damage)
 
 	case "heal", "buff":
@@ -264,9 +430,20 @@ func (e *Engine) applyAbilityToTarget(
 
 	// 2) apply to target health (or healing)
 	if ability.Type == "heal" {
-		target.Health = math.Min(target.MaxHealth, target.Health+impact)
+		// only what the target was missing counts as healed
+		impact = math.Min(impact, target.MaxHealth-target.Health)
+		target.Health += impact
+		e.emitFor(EventHeal, source, target, Event{Ability: ability.ID, Amount: impact})
 	} else {
+		wasUp := target.Health > 0
 		target.Health = math.Max(0, target.Health-impact)
+		if impact > 0 {
+			e.emitFor(EventDamage, source, target, Event{Ability: ability.ID, Amount: impact, Crit: crit})
+			e.wake(target)
+		}
+		if wasUp && target.Health <= 0 {
+			e.emitFor(EventCharacterDown, source, target, Event{Ability: ability.ID})
+		}
 	}
 
 	// 3) if ability grants a buff to the target (or source for self‐buff)
@@ -277,6 +454,7 @@ func (e *Engine) applyAbilityToTarget(
 		if b.Type == "shield" {
 			// ModifierPct is the shield amount
 			target.Shields += int(b.ModifierPercent)
+			e.emitFor(EventBuffApplied, source, target, Event{Ability: ability.ID, Stat: b.Type, Amount: b.ModifierPercent})
 
 		} else {
 			// 4b) all other buffs: compute spirit‐scaled percentage
@@ -286,38 +464,45 @@ func (e *Engine) applyAbilityToTarget(
 			}
 			// push the new buff instance
 			target.ActiveBuffs = append(target.ActiveBuffs, BuffInstance{
+				AppliedBy:     source.ID,
 				Stat:          b.Type, // e.g. "defense" or "strength"
 				ModifierPct:   adj,
 				TotalRounds:   b.Rounds,
 				RoundsApplied: 0,
 			})
+			e.emitFor(EventBuffApplied, source, target, Event{Ability: ability.ID, Stat: b.Type, Amount: adj, Rounds: b.Rounds})
 		}
 	}
 
 	// 4) if ability has a debuff, maybe apply it
 	if ability.Debuff != nil {
 		// 4a) roll for applicationChance
-		if rand.Float64()*100 < ability.Debuff.ApplicationChance {
+		if e.Rand.Float64()*100 < ability.Debuff.ApplicationChance {
 			db := ability.Debuff // alias for brevity
-			// 4b) element‐type debuff: reset or add element
+			// 4b) element‐type debuff: reset or lend the element
 			if db.Type == "element" && db.ElementToApply != "" {
-				// see if there’s an existing element‐debuff of this type
+				// see if there’s an existing element‐debuff lending the same element
 				for i := range target.ActiveDebuffs {
 					inst := &target.ActiveDebuffs[i]
-					if inst.Stat == db.Type {
+					if inst.Stat == db.Type && inst.ElementToApply == db.ElementToApply {
 						// reset its duration
 						inst.RoundsApplied = 0
+						e.emitFor(EventDebuffRefreshed, source, target, Event{
+							Ability: ability.ID,
+							Stat:    db.Type,
+							Element: db.ElementToApply,
+							Rounds:  inst.TotalRounds,
+						})
 						goto appliedDone
 					}
 				}
 				// if target already has the base element, skip
-				for _, el := range target.Elements {
-					if el == db.ElementToApply {
-						goto appliedDone
-					}
+				if slices.Contains(target.Elements, db.ElementToApply) {
+					e.emitFor(EventElementUnchanged, source, target, Event{Ability: ability.ID, Element: db.ElementToApply})
+					goto appliedDone
 				}
-				// otherwise add the element status
-				target.Elements = append(target.Elements, db.ElementToApply)
+				// otherwise the debuff instance below lends it until it expires
+				e.emitFor(EventElementGained, source, target, Event{Ability: ability.ID, Element: db.ElementToApply})
 			}
 
 			// 4c) non‐element debuff (or element with no ElementToApply)
@@ -330,6 +515,8 @@ func (e *Engine) applyAbilityToTarget(
 			// push a fresh instance
 			target.ActiveDebuffs = append(target.ActiveDebuffs, DebuffInstance{
 				AppliedBy:      source.ID,
+				AppliedByAlly:  source.IsAlly,
+				AbilityID:      ability.ID,
 				Stat:           db.Type,
 				ModifierPct:    adj,
 				DamagePercent:  db.DamagePercent,
@@ -338,6 +525,15 @@ func (e *Engine) applyAbilityToTarget(
 				RoundsApplied:  0,
 				Element:        db.Element,
 			})
+			e.emitFor(EventDebuffApplied, source, target, Event{
+				Ability: ability.ID,
+				Stat:    db.Type,
+				Amount:  adj,
+				Element: db.ElementToApply,
+				Rounds:  db.Rounds,
+			})
+		} else {
+			e.emitFor(EventDebuffResisted, source, target, Event{Ability: ability.ID, Stat: ability.Debuff.Type})
 		}
 	appliedDone:
 		// regardless of success or skip, we keep the impact from the damage above
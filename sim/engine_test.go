@@ -0,0 +1,113 @@
+package sim
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestReset(t *testing.T) {
+	team := func(id string, isAlly bool) []*Character { return []*Character{fighter(id, isAlly, 5)} }
+	tests := []struct {
+		name    string
+		engine  func() *Engine
+		allies  []*Character
+		enemies []*Character
+		ok      bool
+	}{
+		{"seeded", func() *Engine { return NewEngine(team("a", true), team("e", false), 3) },
+			team("a", true), team("e", false), true},
+		{"from a source", func() *Engine {
+			return NewEngineWithSource(team("a", true), team("e", false), rand.NewSource(3))
+		}, team("a", true), team("e", false), false},
+		{"empty allies", func() *Engine { return NewEngine(team("a", true), team("e", false), 3) },
+			nil, team("e", false), false},
+		{"nil enemy", func() *Engine { return NewEngine(team("a", true), team("e", false), 3) },
+			team("a", true), []*Character{nil}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := tt.engine()
+			before := e.Characters
+			err := e.Reset(tt.allies, tt.enemies)
+			if (err == nil) != tt.ok {
+				t.Fatalf("Reset error %v, want ok %v", err, tt.ok)
+			}
+			if err != nil && e.Characters[0] != before[0] {
+				t.Error("a failed Reset changed the engine")
+			}
+		})
+	}
+}
+
+func TestResetReplaysSeed(t *testing.T) {
+	play := func(e *Engine) (won bool, turns int) {
+		policy, _ := NewTeamPolicies("random", "random", nil)
+		for !e.GameOver {
+			e.Step(policy)
+		}
+		return e.PlayerWon, e.TotalTurns
+	}
+	teams := func() ([]*Character, []*Character) {
+		a, e := fighter("a", true, 5), fighter("e", false, 6)
+		a.Abilities, e.Abilities = []*Ability{testBash}, []*Ability{testBash}
+		return []*Character{a}, []*Character{e}
+	}
+	allies, enemies := teams()
+	e := NewEngine(allies, enemies, 9)
+	wonA, turnsA := play(e)
+	if err := e.Reset(teams()); err != nil {
+		t.Fatal(err)
+	}
+	if wonB, turnsB := play(e); wonA != wonB || turnsA != turnsB {
+		t.Errorf("after Reset: won %v in %d turns, first time %v in %d", wonB, turnsB, wonA, turnsA)
+	}
+}
+
+// TestLentElementExpires lends a fighter with no elements fire for two rounds
+// and checks fire is gone once the debuff's last round ends, with one
+// ElementLost to say so. A fighter that is fire already keeps it and loses
+// nothing.
+func TestLentElementExpires(t *testing.T) {
+	kindle := &Ability{ID: "kindle", Type: "debuff", TargetType: "single", TargetSelectType: "enemy",
+		Debuff: &Debuff{Type: "element", ElementToApply: Fire, Rounds: 2, ApplicationChance: 100}}
+	tests := []struct {
+		name     string
+		native   bool
+		wantLost int
+	}{
+		{"lent", false, 1},
+		{"already fire", true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caster, target := fighter("a", true, 10), fighter("e", false, 5)
+			if tt.native {
+				target.Elements = []Element{Fire}
+			}
+			e := NewEngine([]*Character{caster}, []*Character{target}, 1)
+			lost, lostRound := 0, -1
+			e.Events = EventSinkFunc(func(ev Event) {
+				if ev.Type == EventElementLost && ev.Target == "e" && ev.Element == Fire {
+					lost++
+					lostRound = e.TotalRounds
+				}
+			})
+			policy := scripted(map[string][]move{"a": {{kindle, "e"}}})
+			for e.TotalRounds < 4 && !e.GameOver {
+				e.Step(policy)
+				// lent in round 1, held through round 2 and gone after it
+				want := tt.native || e.TotalRounds < 2
+				if got := slices.Contains(target.CurrentElements(), Fire); got != want {
+					t.Fatalf("round %d: fire %v, want %v", e.TotalRounds+1, got, want)
+				}
+			}
+			if lost != tt.wantLost {
+				t.Errorf("%d ElementLost events, want %d", lost, tt.wantLost)
+			}
+			if tt.wantLost > 0 && lostRound != 1 {
+				t.Errorf("fire lost in round %d, want at the end of round 2", lostRound+1)
+			}
+		})
+	}
+}
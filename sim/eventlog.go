@@ -0,0 +1,30 @@
+package sim
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONLWriter is an EventSink that writes each event as one JSON object per line.
+type JSONLWriter struct {
+	enc *json.Encoder
+	err error
+}
+
+// NewJSONLWriter returns a sink writing JSON Lines to w.
+func NewJSONLWriter(w io.Writer) *JSONLWriter {
+	return &JSONLWriter{enc: json.NewEncoder(w)}
+}
+
+// Emit writes ev. After the first write error, later events are dropped.
+func (j *JSONLWriter) Emit(ev Event) {
+	if j.err != nil {
+		return
+	}
+	j.err = j.enc.Encode(ev)
+}
+
+// Err returns the first write error, if any.
+func (j *JSONLWriter) Err() error {
+	return j.err
+}
@@ -0,0 +1,99 @@
+package sim
+
+// EventType names one kind of thing that happened during a battle.
+type EventType string
+
+const (
+	EventTurnStarted      EventType = "turn_started"
+	EventTurnSkipped      EventType = "turn_skipped"   // actor had no valid action, Stat names the control effect that stopped it, or Ability names its charge
+	EventChargeStarted    EventType = "charge_started" // actor committed to a charge ability; Rounds is how many of its turns until it lands
+	EventAbilityUsed      EventType = "ability_used"
+	EventManaChanged      EventType = "mana_changed"
+	EventMissed           EventType = "missed"
+	EventShieldAbsorbed   EventType = "shield_absorbed"
+	EventDamage           EventType = "damage"
+	EventHeal             EventType = "heal"
+	EventBuffApplied      EventType = "buff_applied"
+	EventDebuffApplied    EventType = "debuff_applied"
+	EventDebuffResisted   EventType = "debuff_resisted"
+	EventDebuffRefreshed  EventType = "debuff_refreshed"  // a debuff landed on a target that already had it; its duration restarts
+	EventElementUnchanged EventType = "element_unchanged" // an element debuff landed on a target that has the element as a base element
+	EventElementGained    EventType = "element_gained"
+	EventElementLost      EventType = "element_lost" // a lent element wore off
+	EventEffectExpired    EventType = "effect_expired"
+	EventDoTTick          EventType = "dot_tick"
+	EventCharacterDown    EventType = "character_down"
+	EventRoundEnded       EventType = "round_ended"
+	EventBattleEnded      EventType = "battle_ended"
+)
+
+// Event is one entry of the battle log. Fields that don't apply to a given
+// Type are left at their zero value.
+type Event struct {
+	Type         EventType `json:"type"`
+	Round        int       `json:"round"` // 1-based round the event happened in
+	Turn         int       `json:"turn"`  // Engine.TotalTurns at the time
+	Actor        string    `json:"actor,omitempty"`
+	ActorIsAlly  bool      `json:"actor_is_ally,omitempty"`
+	Target       string    `json:"target,omitempty"`
+	TargetIsAlly bool      `json:"target_is_ally,omitempty"`
+	Ability      string    `json:"ability,omitempty"`
+	Amount       float64   `json:"amount,omitempty"` // damage, heal, mana delta, modifier %
+	Stat         string    `json:"stat,omitempty"`   // buff/debuff type, e.g. "defense" or "poison"
+	Element      Element   `json:"element,omitempty"`
+	Rounds       int       `json:"rounds,omitempty"` // duration of an applied effect
+	PlayerWon    bool      `json:"player_won,omitempty"`
+	TimedOut     bool      `json:"timed_out,omitempty"` // battle_ended at MAX_ROUNDS
+	Crit         bool      `json:"crit,omitempty"`      // damage from a critical hit
+	Charged      bool      `json:"charged,omitempty"`   // ability_used at the payoff of a charge started earlier
+}
+
+// EventSink receives every event an Engine emits, in order.
+type EventSink interface {
+	Emit(ev Event)
+}
+
+// EventSinkFunc adapts a plain function to an EventSink.
+type EventSinkFunc func(ev Event)
+
+func (f EventSinkFunc) Emit(ev Event) { f(ev) }
+
+// MultiSink fans each event out to several sinks.
+type MultiSink []EventSink
+
+func (m MultiSink) Emit(ev Event) {
+	for _, s := range m {
+		s.Emit(ev)
+	}
+}
+
+// EventRecorder keeps every event in memory.
+type EventRecorder struct {
+	Events []Event
+}
+
+func (r *EventRecorder) Emit(ev Event) { r.Events = append(r.Events, ev) }
+
+// emit stamps ev with the current round/turn and hands it to the sink, if any.
+func (e *Engine) emit(ev Event) {
+	if e.Events == nil {
+		return
+	}
+	ev.Round = e.TotalRounds + 1
+	ev.Turn = e.TotalTurns
+	e.Events.Emit(ev)
+}
+
+// emitFor is emit with actor and target filled in from characters.
+func (e *Engine) emitFor(t EventType, actor, target *Character, ev Event) {
+	ev.Type = t
+	if actor != nil {
+		ev.Actor = actor.ID
+		ev.ActorIsAlly = actor.IsAlly
+	}
+	if target != nil {
+		ev.Target = target.ID
+		ev.TargetIsAlly = target.IsAlly
+	}
+	e.emit(ev)
+}
@@ -3,13 +3,14 @@ package sim
 import "fmt"
 
 // MakeTeam looks up each key in CharacterTemplates, applies the growth formulas,
-// and returns a slice of *Character ready for battle.
-func MakeTeam(keys []string, level int, isAlly bool) []*Character {
+// and returns a slice of *Character ready for battle. An unknown character
+// key, or a template naming an ability AbilityDict doesn't have, is an error.
+func MakeTeam(keys []string, level int, isAlly bool) ([]*Character, error) {
     team := make([]*Character, 0, len(keys))
     for _, key := range keys {
         tmpl, ok := CharacterTemplates[key]
         if !ok {
-            panic(fmt.Sprintf("unknown character key %q", key))
+            return nil, fmt.Errorf("unknown character key %q", key)
         }
         c := &Character{
             ID:        key,
@@ -24,6 +25,7 @@ func MakeTeam(keys []string, level int, isAlly bool) []*Character {
             Spirit:    tmpl.BaseSpirit + tmpl.SpiritGrowth*float64(level),
             Speed:     tmpl.BaseSpeed + tmpl.SpeedGrowth*float64(level),
             Evasion:   tmpl.Evasion,
+            CritChance: tmpl.CritChance,
 			Shields:   0,
         }
 		// Create a shallow copy of the elements slice to avoid modifying the original template
@@ -33,13 +35,15 @@ func MakeTeam(keys []string, level int, isAlly bool) []*Character {
 
         // Filter abilities the character can actually use at this level
         for _, at := range tmpl.AbilityTemplates {
+			ab, ok := AbilityDict[at.Key]
+			if !ok {
+				return nil, fmt.Errorf("character %q: unknown ability %q", key, at.Key)
+			}
 			if at.MinLv <= level {
-			  if ab, ok := AbilityDict[at.Key]; ok {
 				c.Abilities = append(c.Abilities, ab)
-			  }
 			}
 		  }
         team = append(team, c)
     }
-    return team
+    return team, nil
 }
\ No newline at end of file
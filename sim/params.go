@@ -0,0 +1,286 @@
+package sim
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Param is one tunable number in a DataPack: a numeric field of a character
+// template or an ability, with the range it may move in and a step size to
+// move it by.
+type Param struct {
+	Name  string  `json:"name"` // "character.<id>.<field>" or "ability.<id>.<field>"
+	Kind  string  `json:"kind"` // "character" or "ability"
+	ID    string  `json:"id"`
+	Field string  `json:"field"` // the field's JSON path, e.g. "base_speed" or "debuff.rounds"
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Step  float64 `json:"step"`
+	Whole bool    `json:"whole,omitempty"` // moves in whole units, like mana or turns
+}
+
+// Param kinds.
+const (
+	ParamCharacter = "character"
+	ParamAbility   = "ability"
+)
+
+// CharacterFields maps each tunable template field's JSON name to the field
+// itself.
+var CharacterFields = map[string]func(*CharacterTemplate) *float64{
+	"base_health":     func(t *CharacterTemplate) *float64 { return &t.BaseHealth },
+	"base_mana":       func(t *CharacterTemplate) *float64 { return &t.BaseMana },
+	"base_strength":   func(t *CharacterTemplate) *float64 { return &t.BaseStrength },
+	"base_defense":    func(t *CharacterTemplate) *float64 { return &t.BaseDefense },
+	"base_spirit":     func(t *CharacterTemplate) *float64 { return &t.BaseSpirit },
+	"base_speed":      func(t *CharacterTemplate) *float64 { return &t.BaseSpeed },
+	"hp_growth":       func(t *CharacterTemplate) *float64 { return &t.HPGrowth },
+	"strength_growth": func(t *CharacterTemplate) *float64 { return &t.StrengthGrowth },
+	"defense_growth":  func(t *CharacterTemplate) *float64 { return &t.DefenseGrowth },
+	"spirit_growth":   func(t *CharacterTemplate) *float64 { return &t.SpiritGrowth },
+	"speed_growth":    func(t *CharacterTemplate) *float64 { return &t.SpeedGrowth },
+	"evasion":         func(t *CharacterTemplate) *float64 { return &t.Evasion },
+	"crit_chance":     func(t *CharacterTemplate) *float64 { return &t.CritChance },
+}
+
+// AbilityField reads and writes one tunable number of an ability. Get
+// reports false where the field doesn't apply: the buff and debuff fields of
+// an ability without one, and the power of an ability that neither attacks
+// nor heals.
+type AbilityField struct {
+	Get   func(*Ability) (float64, bool)
+	Set   func(*Ability, float64)
+	Whole bool // stored or spent in whole units; Set rounds
+}
+
+// AbilityFields maps each tunable ability field's JSON path to the field.
+var AbilityFields = map[string]AbilityField{
+	"power": {
+		Get: func(a *Ability) (float64, bool) { return a.Power, a.Type == "attack" || a.Type == "heal" },
+		Set: func(a *Ability, v float64) { a.Power = v },
+	},
+	"mana_cost": {
+		Get:   func(a *Ability) (float64, bool) { return a.ManaCost, true },
+		Set:   func(a *Ability, v float64) { a.ManaCost = v },
+		Whole: true,
+	},
+	"cooldown":     intField(func(a *Ability) *int { return &a.Cooldown }),
+	"uses":         intField(func(a *Ability) *int { return &a.Uses }),
+	"charge_turns": intField(func(a *Ability) *int { return &a.ChargeTurns }),
+
+	"buff.rounds":           buffField(func(b *Buff) *int { return &b.Rounds }, nil),
+	"buff.modifier_percent": buffField(nil, func(b *Buff) *float64 { return &b.ModifierPercent }),
+
+	"debuff.rounds":             debuffField(func(d *Debuff) *int { return &d.Rounds }, nil),
+	"debuff.modifier_percent":   debuffField(nil, func(d *Debuff) *float64 { return &d.ModifierPercent }),
+	"debuff.damage_percent":     debuffField(nil, func(d *Debuff) *float64 { return &d.DamagePercent }),
+	"debuff.application_chance": debuffField(nil, func(d *Debuff) *float64 { return &d.ApplicationChance }),
+}
+
+func intField(f func(*Ability) *int) AbilityField {
+	return AbilityField{
+		Get:   func(a *Ability) (float64, bool) { return float64(*f(a)), true },
+		Set:   func(a *Ability, v float64) { *f(a) = int(math.Round(v)) },
+		Whole: true,
+	}
+}
+
+// buffField and debuffField reach into an ability's buff or debuff; exactly
+// one of whole and frac is given.
+func buffField(whole func(*Buff) *int, frac func(*Buff) *float64) AbilityField {
+	return nestedField(func(a *Ability) *Buff { return a.Buff }, whole, frac)
+}
+
+func debuffField(whole func(*Debuff) *int, frac func(*Debuff) *float64) AbilityField {
+	return nestedField(func(a *Ability) *Debuff { return a.Debuff }, whole, frac)
+}
+
+func nestedField[T any](of func(*Ability) *T, whole func(*T) *int, frac func(*T) *float64) AbilityField {
+	if whole != nil {
+		return AbilityField{
+			Get: func(a *Ability) (float64, bool) {
+				if t := of(a); t != nil {
+					return float64(*whole(t)), true
+				}
+				return 0, false
+			},
+			Set:   func(a *Ability, v float64) { *whole(of(a)) = int(math.Round(v)) },
+			Whole: true,
+		}
+	}
+	return AbilityField{
+		Get: func(a *Ability) (float64, bool) {
+			if t := of(a); t != nil {
+				return *frac(t), true
+			}
+			return 0, false
+		},
+		Set: func(a *Ability, v float64) { *frac(of(a)) = v },
+	}
+}
+
+// zeroSteps is how far a field that is zero moves at a time, since a tenth
+// of nothing goes nowhere.
+var zeroSteps = map[string]float64{
+	"base_health":             5,
+	"base_mana":               1,
+	"base_strength":           1,
+	"base_defense":            1,
+	"base_spirit":             1,
+	"base_speed":              1,
+	"hp_growth":               0.5,
+	"strength_growth":         0.25,
+	"defense_growth":          0.25,
+	"spirit_growth":           0.25,
+	"speed_growth":            0.25,
+	"power":                   5,
+	"buff.modifier_percent":   5,
+	"debuff.modifier_percent": 5,
+	"debuff.damage_percent":   1,
+}
+
+// Get returns the parameter's value in pack.
+func (p Param) Get(pack *DataPack) float64 {
+	if p.Kind == ParamCharacter {
+		t := pack.Characters[p.ID]
+		return *CharacterFields[p.Field](&t)
+	}
+	v, _ := AbilityFields[p.Field].Get(pack.Abilities[p.ID])
+	return v
+}
+
+// Set stores v, clamped to [Min, Max] and rounded to six decimals so repeated
+// steps don't leave float dust behind, or to a whole number if p is Whole, in
+// pack. Abilities are shared by pointer, so pack should be a Clone of
+// anything that must stay untouched.
+func (p Param) Set(pack *DataPack, v float64) {
+	v = min(max(v, p.Min), p.Max)
+	if p.Whole {
+		v = math.Round(v)
+	} else {
+		v = math.Round(v*1e6) / 1e6
+	}
+	if p.Kind == ParamCharacter {
+		t := pack.Characters[p.ID]
+		*CharacterFields[p.Field](&t) = v
+		pack.Characters[p.ID] = t
+		return
+	}
+	AbilityFields[p.Field].Set(pack.Abilities[p.ID], v)
+}
+
+// Characters returns the characters whose win rate the parameter moves
+// directly: the character itself, or everyone who has the ability.
+func (p Param) Characters(pack *DataPack) []string {
+	if p.Kind == ParamCharacter {
+		return []string{p.ID}
+	}
+	var ids []string
+	for _, id := range slices.Sorted(maps.Keys(pack.Characters)) {
+		for _, at := range pack.Characters[id].AbilityTemplates {
+			if at.Key == p.ID {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// SelectParams returns a Param for every field of pack whose name matches one
+// of the patterns, sorted by name. Patterns are path.Match globs over names
+// like "character.mycera.base_speed" or "ability.scorch.debuff.rounds", so
+// "character.*.evasion" or "ability.*.power" select a field everywhere.
+// Fields an ability doesn't use, such as the debuff fields of an attack, are
+// never selected.
+//
+// Bounds and step come from the current value: half to one and a half times
+// it in steps of a tenth. A field that is zero moves by a fixed step for its
+// kind, up to ten of them, and negative values are left out. Evasion and
+// crit chance move by 0.02 within [0, 0.5], and application chances by 5
+// within [5, 100]. Mana costs move by 1 down to 1, and abilities that cost
+// no mana are left out. Rounds, cooldowns, uses and charge turns move by 1
+// between half and twice their value, never back to 0 once set, or from 0 up
+// to 2; rounds and uses of 0 are left out, as there 0 means no duration or
+// no limit.
+func SelectParams(pack *DataPack, patterns []string) ([]Param, error) {
+	var out []Param
+	matched := make([]bool, len(patterns))
+	consider := func(kind, id, field string, v float64, whole bool) error {
+		name := kind + "." + id + "." + field
+		hit := false
+		for i, pat := range patterns {
+			ok, err := path.Match(pat, name)
+			if err != nil {
+				return fmt.Errorf("param pattern %q: %w", pat, err)
+			}
+			if ok {
+				matched[i], hit = true, true
+			}
+		}
+		if !hit {
+			return nil
+		}
+		p := Param{Name: name, Kind: kind, ID: id, Field: field, Whole: whole}
+		switch {
+		case field == "evasion" || field == "crit_chance":
+			p.Min, p.Max, p.Step = 0, 0.5, 0.02
+		case field == "debuff.application_chance":
+			p.Min, p.Max, p.Step = 5, 100, 5
+		case field == "mana_cost":
+			if v <= 0 {
+				return nil
+			}
+			p.Min, p.Max, p.Step = 1, math.Max(2*v, v+2), 1
+		case whole:
+			if v <= 0 && (field == "uses" || strings.HasSuffix(field, ".rounds")) {
+				return nil
+			}
+			p.Min, p.Max, p.Step = math.Floor(v/2), math.Max(2*v, v+2), 1
+			if v > 0 {
+				p.Min = math.Max(p.Min, 1)
+			}
+		case v < 0:
+			return nil
+		case v == 0:
+			p.Min, p.Max, p.Step = 0, 10*zeroSteps[field], zeroSteps[field]
+		default:
+			p.Min, p.Max, p.Step = v/2, 1.5*v, math.Max(v/10, 0.05)
+		}
+		out = append(out, p)
+		return nil
+	}
+
+	for id, t := range pack.Characters {
+		for field, f := range CharacterFields {
+			if err := consider(ParamCharacter, id, field, *f(&t), false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for id, ab := range pack.Abilities {
+		if ab == nil {
+			continue
+		}
+		for field, f := range AbilityFields {
+			v, ok := f.Get(ab)
+			if !ok {
+				continue
+			}
+			if err := consider(ParamAbility, id, field, v, f.Whole); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for i, ok := range matched {
+		if !ok {
+			return nil, fmt.Errorf("param pattern %q matches no field", patterns[i])
+		}
+	}
+	slices.SortFunc(out, func(a, b Param) int { return strings.Compare(a.Name, b.Name) })
+	return out, nil
+}
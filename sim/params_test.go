@@ -0,0 +1,95 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSelectParams(t *testing.T) {
+	pack := &DataPack{
+		Characters: map[string]CharacterTemplate{
+			"hero": {BaseHealth: 40, BaseSpeed: 0, Evasion: 0.1, SpeedGrowth: -1},
+		},
+		Abilities: map[string]*Ability{
+			"hit": {ID: "hit", Type: "attack", Power: 30, ManaCost: 2, Cooldown: 2},
+			"ward": {ID: "ward", Type: "buff", ManaCost: -1, Uses: 0,
+				Buff: &Buff{Type: "defense", Rounds: 1, ModifierPercent: 20}},
+			"rot": {ID: "rot", Type: "debuff", ManaCost: 0, ChargeTurns: 0,
+				Debuff: &Debuff{Type: "poison", Rounds: 4, DamagePercent: 6, ApplicationChance: 80}},
+		},
+	}
+	params, err := SelectParams(pack, []string{"character.*.*", "ability.*.*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]Param{}
+	for _, p := range params {
+		got[p.Name] = p
+	}
+
+	tests := []struct {
+		name           string
+		min, max, step float64
+		whole          bool
+	}{
+		{"character.hero.base_health", 20, 60, 4, false},
+		{"character.hero.base_speed", 0, 10, 1, false}, // zero moves by a fixed step
+		{"character.hero.evasion", 0, 0.5, 0.02, false},
+		{"character.hero.crit_chance", 0, 0.5, 0.02, false},
+		{"ability.hit.power", 15, 45, 3, false},
+		{"ability.hit.mana_cost", 1, 4, 1, true},
+		{"ability.hit.cooldown", 1, 4, 1, true},
+		{"ability.hit.charge_turns", 0, 2, 1, true},
+		{"ability.ward.buff.rounds", 1, 3, 1, true},
+		{"ability.ward.buff.modifier_percent", 10, 30, 2, false},
+		{"ability.rot.debuff.rounds", 2, 8, 1, true},
+		{"ability.rot.debuff.modifier_percent", 0, 50, 5, false},
+		{"ability.rot.debuff.damage_percent", 3, 9, 0.6, false},
+		{"ability.rot.debuff.application_chance", 5, 100, 5, false},
+	}
+	for _, tt := range tests {
+		p, ok := got[tt.name]
+		if !ok {
+			t.Errorf("%s not selected", tt.name)
+			continue
+		}
+		if math.Abs(p.Min-tt.min) > 1e-9 || math.Abs(p.Max-tt.max) > 1e-9 || math.Abs(p.Step-tt.step) > 1e-9 || p.Whole != tt.whole {
+			t.Errorf("%s: [%g, %g] by %g whole %v, want [%g, %g] by %g whole %v",
+				tt.name, p.Min, p.Max, p.Step, p.Whole, tt.min, tt.max, tt.step, tt.whole)
+		}
+	}
+
+	for _, name := range []string{
+		"character.hero.speed_growth", // negative
+		"ability.ward.power",          // a buff doesn't use power
+		"ability.ward.mana_cost",      // refunds mana
+		"ability.ward.uses",           // 0 is no limit
+		"ability.rot.mana_cost",       // free
+		"ability.hit.buff.rounds",     // no buff
+		"ability.ward.debuff.rounds",  // no debuff
+	} {
+		if _, ok := got[name]; ok {
+			t.Errorf("%s selected, want it left out", name)
+		}
+	}
+}
+
+func TestParamSetWhole(t *testing.T) {
+	pack := &DataPack{Abilities: map[string]*Ability{
+		"rot": {ID: "rot", Type: "debuff", Debuff: &Debuff{Type: "poison", Rounds: 4, DamagePercent: 6}},
+	}}
+	rounds := Param{Kind: ParamAbility, ID: "rot", Field: "debuff.rounds", Min: 2, Max: 8, Whole: true}
+	rounds.Set(pack, 5.6)
+	if got := pack.Abilities["rot"].Debuff.Rounds; got != 6 {
+		t.Errorf("rounds set to %d, want 6", got)
+	}
+	rounds.Set(pack, 20)
+	if got := rounds.Get(pack); got != 8 {
+		t.Errorf("rounds set to %g, want the 8 it's capped at", got)
+	}
+	damage := Param{Kind: ParamAbility, ID: "rot", Field: "debuff.damage_percent", Min: 3, Max: 9}
+	damage.Set(pack, 6.6)
+	if got := pack.Abilities["rot"].Debuff.DamagePercent; got != 6.6 {
+		t.Errorf("damage percent set to %g, want 6.6", got)
+	}
+}
@@ -0,0 +1,180 @@
+package sim
+
+import (
+	"fmt"
+	"maps"
+	"math/rand"
+	"slices"
+	"strings"
+)
+
+// Policy decides what a character does on its turn. A Policy value is used
+// for a single battle, so it may keep state from one turn to the next.
+type Policy interface {
+	Name() string
+	Decide(actor *Character, allies, enemies []*Character, rng *rand.Rand) (*Ability, []*Character)
+}
+
+// PolicyFactory builds a fresh Policy for one battle.
+type PolicyFactory func() Policy
+
+// Name lets a bare DecisionFunc be used as a stateless Policy.
+func (f DecisionFunc) Name() string { return "func" }
+
+func (f DecisionFunc) Decide(actor *Character, allies, enemies []*Character, rng *rand.Rand) (*Ability, []*Character) {
+	return f(actor, allies, enemies, rng)
+}
+
+// namedPolicy is a stateless Policy wrapping a DecisionFunc under a name.
+type namedPolicy struct {
+	name string
+	fn   DecisionFunc
+}
+
+func (p namedPolicy) Name() string { return p.name }
+
+func (p namedPolicy) Decide(actor *Character, allies, enemies []*Character, rng *rand.Rand) (*Ability, []*Character) {
+	return p.fn(actor, allies, enemies, rng)
+}
+
+var policyRegistry = map[string]PolicyFactory{}
+
+// RegisterPolicy makes a policy available to NewPolicy under name. It panics
+// if the name is already taken.
+func RegisterPolicy(name string, factory PolicyFactory) {
+	if _, dup := policyRegistry[name]; dup {
+		panic(fmt.Sprintf("policy %q registered twice", name))
+	}
+	policyRegistry[name] = factory
+}
+
+// RegisterDecisionFunc registers a stateless policy built from fn.
+func RegisterDecisionFunc(name string, fn DecisionFunc) {
+	RegisterPolicy(name, func() Policy { return namedPolicy{name, fn} })
+}
+
+// NewPolicy builds a fresh instance of the policy registered as name.
+func NewPolicy(name string) (Policy, error) {
+	factory, ok := policyRegistry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown policy %q (have %s)", name, strings.Join(PolicyNames(), ", "))
+	}
+	return factory(), nil
+}
+
+// PolicyNames returns every registered policy name, sorted.
+func PolicyNames() []string {
+	return slices.Sorted(maps.Keys(policyRegistry))
+}
+
+func init() {
+	RegisterDecisionFunc("random", RandomDecision)
+	RegisterDecisionFunc("utility", UtilityDecision)
+	RegisterDecisionFunc("greedy", GreedyDecision)
+	RegisterPolicy("focus", func() Policy { return &focusPolicy{} })
+}
+
+// focusPolicy plays like UtilityDecision, but once it has hit an enemy with a
+// single-target attack it keeps attacking that enemy until it goes down.
+type focusPolicy struct {
+	target *Character
+}
+
+func (p *focusPolicy) Name() string { return "focus" }
+
+func (p *focusPolicy) Decide(actor *Character, allies, enemies []*Character, rng *rand.Rand) (*Ability, []*Character) {
+	ab, targets := UtilityDecision(actor, allies, enemies, rng)
+	if ab == nil || ab.Type != "attack" || ab.TargetType != "single" || len(targets) != 1 {
+		return ab, targets
+	}
+	opponents := enemies
+	if !actor.IsAlly {
+		opponents = allies
+	}
+	if Taunter(actor, opponents) != nil {
+		// the taunt already decided the target
+		return ab, targets
+	}
+	if p.target != nil && p.target.Health > 0 && p.target.IsAlly != actor.IsAlly {
+		return ab, []*Character{p.target}
+	}
+	if targets[0].IsAlly != actor.IsAlly {
+		p.target = targets[0]
+	}
+	return ab, targets
+}
+
+// Slot picks out the characters of one template on one side of a battle.
+type Slot struct {
+	IsAlly bool
+	ID     string // character template key
+}
+
+func (s Slot) String() string {
+	if s.IsAlly {
+		return "ally:" + s.ID
+	}
+	return "enemy:" + s.ID
+}
+
+// ParseSlot reads a Slot written as "ally:<key>" or "enemy:<key>".
+func ParseSlot(s string) (Slot, error) {
+	side, id, ok := strings.Cut(s, ":")
+	if !ok || id == "" || (side != "ally" && side != "enemy") {
+		return Slot{}, fmt.Errorf("bad slot %q, want ally:<character> or enemy:<character>", s)
+	}
+	return Slot{IsAlly: side == "ally", ID: id}, nil
+}
+
+// TeamPolicies gives each side of a battle its own Policy, with optional
+// overrides for the characters of one template on one side.
+type TeamPolicies struct {
+	Allies    Policy
+	Enemies   Policy
+	Overrides map[Slot]Policy
+}
+
+func (t *TeamPolicies) Name() string {
+	name := t.Allies.Name() + " vs " + t.Enemies.Name()
+	for _, s := range slices.SortedFunc(maps.Keys(t.Overrides), func(a, b Slot) int {
+		return strings.Compare(a.String(), b.String())
+	}) {
+		name += ", " + s.String() + " " + t.Overrides[s].Name()
+	}
+	return name
+}
+
+func (t *TeamPolicies) Decide(actor *Character, allies, enemies []*Character, rng *rand.Rand) (*Ability, []*Character) {
+	if p, ok := t.Overrides[Slot{actor.IsAlly, actor.ID}]; ok {
+		return p.Decide(actor, allies, enemies, rng)
+	}
+	if actor.IsAlly {
+		return t.Allies.Decide(actor, allies, enemies, rng)
+	}
+	return t.Enemies.Decide(actor, allies, enemies, rng)
+}
+
+// NewTeamPolicies builds fresh policies for both sides, and for each
+// overridden slot, from registry names. overrides may be nil.
+func NewTeamPolicies(allyPolicy, enemyPolicy string, overrides map[Slot]string) (*TeamPolicies, error) {
+	a, err := NewPolicy(allyPolicy)
+	if err != nil {
+		return nil, err
+	}
+	b, err := NewPolicy(enemyPolicy)
+	if err != nil {
+		return nil, err
+	}
+	t := &TeamPolicies{Allies: a, Enemies: b}
+	for slot, name := range overrides {
+		p, err := NewPolicy(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", slot, err)
+		}
+		if t.Overrides == nil {
+			t.Overrides = map[Slot]Policy{}
+		}
+		t.Overrides[slot] = p
+	}
+	return t, nil
+}
@@ -0,0 +1,94 @@
+package sim
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestTeamPoliciesOverrides(t *testing.T) {
+	var used string
+	record := func(name string) DecisionFunc {
+		return func(actor *Character, allies, enemies []*Character, rng *rand.Rand) (*Ability, []*Character) {
+			used = name
+			return nil, nil
+		}
+	}
+	policy := &TeamPolicies{
+		Allies:  record("allies"),
+		Enemies: record("enemies"),
+		Overrides: map[Slot]Policy{
+			{IsAlly: true, ID: "mycera"}:    record("ally mycera"),
+			{IsAlly: false, ID: "frostnip"}: record("enemy frostnip"),
+		},
+	}
+	tests := []struct {
+		id     string
+		isAlly bool
+		want   string
+	}{
+		{"mycera", true, "ally mycera"},
+		{"mycera", false, "enemies"},
+		{"frostnip", true, "allies"},
+		{"frostnip", false, "enemy frostnip"},
+		{"pondril", true, "allies"},
+	}
+	for _, tt := range tests {
+		used = ""
+		policy.Decide(&Character{ID: tt.id, IsAlly: tt.isAlly}, nil, nil, nil)
+		if used != tt.want {
+			t.Errorf("%s (ally %v) decided by %q, want %q", tt.id, tt.isAlly, used, tt.want)
+		}
+	}
+}
+
+func TestNewTeamPolicies(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[Slot]string
+		want      string // Name of the result, or "" for an error
+	}{
+		{"none", nil, "utility vs greedy"},
+		{"sorted", map[Slot]string{
+			{IsAlly: false, ID: "mycera"}: "focus",
+			{IsAlly: true, ID: "pondril"}: "random",
+		}, "utility vs greedy, ally:pondril random, enemy:mycera focus"},
+		{"unknown policy", map[Slot]string{{IsAlly: true, ID: "mycera"}: "psychic"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewTeamPolicies("utility", "greedy", tt.overrides)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("no error, got %q", p.Name())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Name() != tt.want {
+				t.Errorf("Name() = %q, want %q", p.Name(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSlot(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Slot
+		ok   bool
+	}{
+		{"ally:mycera", Slot{IsAlly: true, ID: "mycera"}, true},
+		{"enemy:frostnip", Slot{ID: "frostnip"}, true},
+		{"mycera", Slot{}, false},
+		{"ally:", Slot{}, false},
+		{"foe:mycera", Slot{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseSlot(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseSlot(%q) = %v, %v; want %v, ok %v", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
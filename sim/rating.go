@@ -0,0 +1,178 @@
+package sim
+
+import "math"
+
+// Default TrueSkill parameters: new entries start at Mu 25 with a wide
+// Sigma, Beta is the per-battle performance noise and Tau a little drift
+// added before every update so ratings never freeze completely.
+const (
+	RatingMu    = 25.0
+	RatingSigma = RatingMu / 3
+	RatingBeta  = RatingSigma / 2
+	RatingTau   = RatingSigma / 100
+)
+
+// Rating is a skill estimate with its uncertainty.
+type Rating struct {
+	Mu    float64 `json:"mu"`
+	Sigma float64 `json:"sigma"`
+}
+
+// Conservative is the usual leaderboard value, Mu - 3*Sigma: a skill the
+// entry very likely exceeds.
+func (r Rating) Conservative() float64 {
+	return r.Mu - 3*r.Sigma
+}
+
+// Rater keeps TrueSkill-style ratings for teams and characters and updates
+// them one battle at a time. Teams are rated as single entries; characters
+// are rated as members whose skills add up to their team's performance, so
+// a character's rating reflects the teams it was on and the teams it faced.
+type Rater struct {
+	Teams      map[string]*Rating
+	Characters map[string]*Rating
+	Battles    int
+}
+
+// NewRater returns a Rater with nobody rated yet.
+func NewRater() *Rater {
+	return &Rater{
+		Teams:      map[string]*Rating{},
+		Characters: map[string]*Rating{},
+	}
+}
+
+// Record updates the ratings with one battle between teams a and b. Mirror
+// matches carry no information and are skipped.
+func (r *Rater) Record(a, b []string, aWon bool) {
+	ka, kb := TeamKey(a), TeamKey(b)
+	if ka == kb {
+		return
+	}
+	r.Battles++
+
+	winTeam, loseTeam := ka, kb
+	winKeys, loseKeys := a, b
+	if !aWon {
+		winTeam, loseTeam = kb, ka
+		winKeys, loseKeys = b, a
+	}
+	updateRatings(
+		[]*Rating{r.rating(r.Teams, winTeam)},
+		[]*Rating{r.rating(r.Teams, loseTeam)},
+	)
+
+	win := make([]*Rating, len(winKeys))
+	for i, k := range winKeys {
+		win[i] = r.rating(r.Characters, k)
+	}
+	lose := make([]*Rating, len(loseKeys))
+	for i, k := range loseKeys {
+		lose[i] = r.rating(r.Characters, k)
+	}
+	updateRatings(win, lose)
+}
+
+// RecordBatch feeds every battle of res into r. Trials are interleaved across
+// matchups (trial 0 of every matchup, then trial 1, ...) so the order the
+// round-robin was generated in doesn't bias the early updates.
+func (r *Rater) RecordBatch(res *BatchResult) {
+	most := 0
+	for _, m := range res.Matchups {
+		most = max(most, len(m.Outcomes))
+	}
+	for t := 0; t < most; t++ {
+		for _, m := range res.Matchups {
+			if t < len(m.Outcomes) {
+				r.Record(m.Matchup.A, m.Matchup.B, m.Outcomes[t])
+			}
+		}
+	}
+}
+
+// RateBatch returns fresh ratings built from every battle of res.
+func RateBatch(res *BatchResult) *Rater {
+	r := NewRater()
+	r.RecordBatch(res)
+	return r
+}
+
+// Team returns the rating of the team with the given keys, or the prior if
+// it has never played.
+func (r *Rater) Team(keys []string) Rating {
+	if rt, ok := r.Teams[TeamKey(keys)]; ok {
+		return *rt
+	}
+	return Rating{RatingMu, RatingSigma}
+}
+
+// Character returns id's rating, or the prior if it has never played.
+func (r *Rater) Character(id string) Rating {
+	if rt, ok := r.Characters[id]; ok {
+		return *rt
+	}
+	return Rating{RatingMu, RatingSigma}
+}
+
+func (r *Rater) rating(m map[string]*Rating, key string) *Rating {
+	rt, ok := m[key]
+	if !ok {
+		rt = &Rating{RatingMu, RatingSigma}
+		m[key] = rt
+	}
+	return rt
+}
+
+// updateRatings applies the two-team, no-draw TrueSkill update. Each side's
+// performance is the sum of its members' skills plus Beta noise per member.
+// All deltas are computed before any is applied, so an entry that appears on
+// both sides is handled consistently.
+func updateRatings(win, lose []*Rating) {
+	type delta struct {
+		r      *Rating
+		dMu    float64
+		newVar float64
+	}
+
+	sumVar := 0.0
+	muWin, muLose := 0.0, 0.0
+	for _, rt := range win {
+		sumVar += rt.Sigma*rt.Sigma + RatingTau*RatingTau
+		muWin += rt.Mu
+	}
+	for _, rt := range lose {
+		sumVar += rt.Sigma*rt.Sigma + RatingTau*RatingTau
+		muLose += rt.Mu
+	}
+	c2 := sumVar + float64(len(win)+len(lose))*RatingBeta*RatingBeta
+	c := math.Sqrt(c2)
+	t := (muWin - muLose) / c
+	v := normPDF(t) / math.Max(normCDF(t), 1e-300)
+	w := v * (v + t)
+
+	var deltas []delta
+	apply := func(side []*Rating, sign float64) {
+		for _, rt := range side {
+			variance := rt.Sigma*rt.Sigma + RatingTau*RatingTau
+			deltas = append(deltas, delta{
+				r:      rt,
+				dMu:    sign * variance / c * v,
+				newVar: variance * math.Max(1-variance/c2*w, 1e-4),
+			})
+		}
+	}
+	apply(win, 1)
+	apply(lose, -1)
+	for _, d := range deltas {
+		d.r.Mu += d.dMu
+		d.r.Sigma = math.Sqrt(d.newVar)
+	}
+}
+
+func normPDF(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
+
+func normCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
@@ -0,0 +1,53 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+// TestRecordOneGame checks a single game between two newcomers against the
+// no-draw TrueSkill update worked by hand. With c² = 2σ² + 2β² ≈ 173.6 and
+// even odds, v = φ(0)/Φ(0) ≈ 0.798 and w = v² ≈ 0.637, so the winner gains
+// σ²/c × v ≈ 4.205 and both sigmas shrink to σ × sqrt(1 - σ²/c² × w).
+func TestRecordOneGame(t *testing.T) {
+	tests := []struct {
+		name string
+		aWon bool
+	}{
+		{"a wins", true},
+		{"b wins", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRater()
+			r.Record([]string{"a"}, []string{"b"}, tt.aWon)
+			win, lose := r.Character("a"), r.Character("b")
+			if !tt.aWon {
+				win, lose = lose, win
+			}
+			for _, c := range []struct {
+				who       string
+				got       Rating
+				mu, sigma float64
+			}{
+				{"winner", win, 29.205, 7.195},
+				{"loser", lose, 20.795, 7.195},
+			} {
+				if math.Abs(c.got.Mu-c.mu) > 1e-3 || math.Abs(c.got.Sigma-c.sigma) > 1e-3 {
+					t.Errorf("%s rated %.3f ± %.3f, want %.3f ± %.3f", c.who, c.got.Mu, c.got.Sigma, c.mu, c.sigma)
+				}
+			}
+			if team := r.Team([]string{"a"}); team != r.Character("a") {
+				t.Errorf("team of one rated %v, its member %v", team, r.Character("a"))
+			}
+		})
+	}
+}
+
+func TestRecordSkipsMirror(t *testing.T) {
+	r := NewRater()
+	r.Record([]string{"a", "b"}, []string{"a", "b"}, true)
+	if r.Battles != 0 || len(r.Characters) != 0 {
+		t.Errorf("mirror match recorded: %d battles, %d characters rated", r.Battles, len(r.Characters))
+	}
+}
@@ -0,0 +1,296 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand"
+	"slices"
+)
+
+// BattleLog is everything needed to re-run a battle bit-for-bit: the seed,
+// both teams, every decision taken and the state after each step.
+type BattleLog struct {
+	Seed        int64        `json:"seed"`
+	AllyKeys    []string     `json:"ally_keys"`
+	EnemyKeys   []string     `json:"enemy_keys"`
+	AllyLevel   int          `json:"ally_level"`
+	EnemyLevel  int          `json:"enemy_level"`
+	ATB         bool         `json:"atb,omitempty"`
+	Policy      string       `json:"policy"` // name of the policy that made the decisions
+	Steps       []StepRecord `json:"steps"`
+	PlayerWon   bool         `json:"player_won"`
+	TotalRounds int          `json:"total_rounds"`
+	TotalTurns  int          `json:"total_turns"`
+}
+
+// StepRecord is one call to Engine.Step. Actor and Targets are indices into
+// Engine.Characters, since the same key can sit on both sides of a mirror match.
+type StepRecord struct {
+	Turn    int              `json:"turn"`
+	Actor   int              `json:"actor"`
+	Ability string           `json:"ability,omitempty"` // empty if the actor did nothing
+	Targets []int            `json:"targets,omitempty"`
+	State   []CharacterState `json:"state"`
+}
+
+// CharacterState is the part of a Character that replay verifies.
+type CharacterState struct {
+	ID       string           `json:"id"`
+	Health   float64          `json:"health"`
+	Mana     float64          `json:"mana"`
+	Shields  int              `json:"shields"`
+	Elements []Element        `json:"elements"`
+	Buffs    []BuffInstance   `json:"buffs"`
+	Debuffs  []DebuffInstance `json:"debuffs"`
+	ReadyAt  map[string]int   `json:"ready_at,omitempty"`
+	Used     map[string]int   `json:"used,omitempty"`
+	Charging string           `json:"charging,omitempty"` // ability being charged
+}
+
+// Divergence describes the first point where a replay stopped matching its log.
+type Divergence struct {
+	Step      int    // index into BattleLog.Steps
+	Turn      int    // turn number of that step
+	Character int    // index into Engine.Characters, or -1
+	ID        string // character key, if any
+	Field     string // "actor", "ability", "health", "buffs", ...
+	Want      string // value in the log
+	Got       string // value under the current rules
+}
+
+func (d *Divergence) Error() string {
+	who := ""
+	if d.ID != "" {
+		who = fmt.Sprintf(" %s[%d]", d.ID, d.Character)
+	}
+	return fmt.Sprintf("step %d (turn %d):%s %s: recorded %s, replayed %s",
+		d.Step, d.Turn, who, d.Field, d.Want, d.Got)
+}
+
+// RecordBattle runs one battle to completion and returns its log and the
+// finished engine. atb selects the initiative timeline; events may be nil.
+// It fails only if a team can't be built.
+func RecordBattle(
+	allyKeys, enemyKeys []string,
+	allyLevel, enemyLevel int,
+	seed int64,
+	atb bool,
+	policy Policy,
+	events EventSink,
+) (*BattleLog, *Engine, error) {
+	log := &BattleLog{
+		Seed:       seed,
+		AllyKeys:   slices.Clone(allyKeys),
+		EnemyKeys:  slices.Clone(enemyKeys),
+		AllyLevel:  allyLevel,
+		EnemyLevel: enemyLevel,
+		ATB:        atb,
+		Policy:     policy.Name(),
+	}
+	allies, err := MakeTeam(allyKeys, allyLevel, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	enemies, err := MakeTeam(enemyKeys, enemyLevel, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	e := NewEngine(allies, enemies, seed)
+	if atb {
+		e.UseATB()
+	}
+	e.Events = events
+
+	for !e.GameOver {
+		rec := StepRecord{Actor: e.TurnOrder[e.Current]}
+		e.Step(DecisionFunc(func(actor *Character, allies, enemies []*Character, rng *rand.Rand) (*Ability, []*Character) {
+			ab, targets := policy.Decide(actor, allies, enemies, rng)
+			if ab != nil && len(targets) > 0 {
+				rec.Ability = ab.ID
+				for _, t := range targets {
+					rec.Targets = append(rec.Targets, e.indexOf(t))
+				}
+			}
+			return ab, targets
+		}))
+		rec.Turn = e.TotalTurns
+		rec.State = e.snapshot()
+		log.Steps = append(log.Steps, rec)
+	}
+	log.PlayerWon = e.PlayerWon
+	log.TotalRounds = e.TotalRounds
+	log.TotalTurns = e.TotalTurns
+	return log, e, nil
+}
+
+// Replay re-runs log through a fresh Engine under the current rules, forcing
+// the recorded decisions, and returns the first step whose state differs from
+// the recording. It returns nil if the whole battle reproduces exactly.
+func Replay(log *BattleLog) *Divergence {
+	allies, err := MakeTeam(log.AllyKeys, log.AllyLevel, true)
+	if err != nil {
+		return &Divergence{Character: -1, Field: "ally team", Want: fmt.Sprint(log.AllyKeys), Got: err.Error()}
+	}
+	enemies, err := MakeTeam(log.EnemyKeys, log.EnemyLevel, false)
+	if err != nil {
+		return &Divergence{Character: -1, Field: "enemy team", Want: fmt.Sprint(log.EnemyKeys), Got: err.Error()}
+	}
+	e := NewEngine(allies, enemies, log.Seed)
+	if log.ATB {
+		e.UseATB()
+	}
+
+	for i, rec := range log.Steps {
+		if e.GameOver {
+			return &Divergence{Step: i, Turn: rec.Turn, Character: -1, Field: "game over",
+				Want: "false", Got: "true"}
+		}
+		if actor := e.TurnOrder[e.Current]; actor != rec.Actor {
+			return &Divergence{Step: i, Turn: rec.Turn, Character: actor, ID: e.Characters[actor].ID,
+				Field: "actor", Want: describeIndex(e, rec.Actor), Got: describeIndex(e, actor)}
+		}
+
+		var forceErr *Divergence
+		e.Step(DecisionFunc(func(actor *Character, allies, enemies []*Character, rng *rand.Rand) (*Ability, []*Character) {
+			if rec.Ability == "" {
+				return nil, nil
+			}
+			var ab *Ability
+			for _, a := range actor.Abilities {
+				if a.ID == rec.Ability {
+					ab = a
+				}
+			}
+			if ab == nil {
+				forceErr = &Divergence{Field: "ability", Want: rec.Ability, Got: "not available"}
+				return nil, nil
+			}
+			targets := make([]*Character, 0, len(rec.Targets))
+			for _, t := range rec.Targets {
+				// logs are read from disk; don't trust their indices
+				if t < 0 || t >= len(e.Characters) {
+					forceErr = &Divergence{Field: "target", Want: fmt.Sprintf("#%d", t), Got: "no such character"}
+					return nil, nil
+				}
+				targets = append(targets, e.Characters[t])
+			}
+			return ab, targets
+		}))
+		if forceErr != nil {
+			forceErr.Step, forceErr.Turn = i, rec.Turn
+			forceErr.Character, forceErr.ID = rec.Actor, e.Characters[rec.Actor].ID
+			return forceErr
+		}
+		if e.TotalTurns != rec.Turn {
+			return &Divergence{Step: i, Turn: rec.Turn, Character: -1, Field: "turn",
+				Want: fmt.Sprint(rec.Turn), Got: fmt.Sprint(e.TotalTurns)}
+		}
+		if d := compareStates(rec.State, e.snapshot()); d != nil {
+			d.Step, d.Turn = i, rec.Turn
+			return d
+		}
+	}
+
+	if !e.GameOver {
+		return &Divergence{Step: len(log.Steps), Character: -1, Field: "game over", Want: "true", Got: "false"}
+	}
+	if e.PlayerWon != log.PlayerWon {
+		return &Divergence{Step: len(log.Steps), Character: -1, Field: "winner",
+			Want: fmt.Sprint(log.PlayerWon), Got: fmt.Sprint(e.PlayerWon)}
+	}
+	return nil
+}
+
+// WriteBattleLog encodes log as indented JSON.
+func WriteBattleLog(w io.Writer, log *BattleLog) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+// ReadBattleLog decodes a log written by WriteBattleLog.
+func ReadBattleLog(r io.Reader) (*BattleLog, error) {
+	var log BattleLog
+	if err := json.NewDecoder(r).Decode(&log); err != nil {
+		return nil, fmt.Errorf("read battle log: %w", err)
+	}
+	return &log, nil
+}
+
+// snapshot copies the verifiable state of every character.
+func (e *Engine) snapshot() []CharacterState {
+	out := make([]CharacterState, len(e.Characters))
+	for i, c := range e.Characters {
+		out[i] = CharacterState{
+			ID:       c.ID,
+			Health:   c.Health,
+			Mana:     c.Mana,
+			Shields:  c.Shields,
+			Elements: slices.Clone(c.CurrentElements()),
+			Buffs:    slices.Clone(c.ActiveBuffs),
+			Debuffs:  slices.Clone(c.ActiveDebuffs),
+			ReadyAt:  maps.Clone(c.ReadyAt),
+			Used:     maps.Clone(c.Used),
+		}
+		if c.Charging != nil {
+			out[i].Charging = c.Charging.Ability.ID
+		}
+	}
+	return out
+}
+
+// indexOf returns c's index in e.Characters, or -1.
+func (e *Engine) indexOf(c *Character) int {
+	for i, x := range e.Characters {
+		if x == c {
+			return i
+		}
+	}
+	return -1
+}
+
+func describeIndex(e *Engine, i int) string {
+	if i < 0 || i >= len(e.Characters) {
+		return fmt.Sprintf("#%d", i)
+	}
+	return fmt.Sprintf("%s[%d]", e.Characters[i].ID, i)
+}
+
+// compareStates reports the first field that differs between want and got.
+func compareStates(want, got []CharacterState) *Divergence {
+	if len(want) != len(got) {
+		return &Divergence{Character: -1, Field: "characters",
+			Want: fmt.Sprint(len(want)), Got: fmt.Sprint(len(got))}
+	}
+	for i := range want {
+		w, g := want[i], got[i]
+		diff := func(field string, a, b any) *Divergence {
+			return &Divergence{Character: i, ID: w.ID, Field: field, Want: fmt.Sprint(a), Got: fmt.Sprint(b)}
+		}
+		switch {
+		case w.ID != g.ID:
+			return diff("id", w.ID, g.ID)
+		case w.Health != g.Health:
+			return diff("health", w.Health, g.Health)
+		case w.Mana != g.Mana:
+			return diff("mana", w.Mana, g.Mana)
+		case w.Shields != g.Shields:
+			return diff("shields", w.Shields, g.Shields)
+		case !slices.Equal(w.Elements, g.Elements):
+			return diff("elements", w.Elements, g.Elements)
+		case !slices.Equal(w.Buffs, g.Buffs):
+			return diff("buffs", w.Buffs, g.Buffs)
+		case !slices.Equal(w.Debuffs, g.Debuffs):
+			return diff("debuffs", w.Debuffs, g.Debuffs)
+		case !maps.Equal(w.ReadyAt, g.ReadyAt):
+			return diff("cooldowns", w.ReadyAt, g.ReadyAt)
+		case !maps.Equal(w.Used, g.Used):
+			return diff("uses", w.Used, g.Used)
+		case w.Charging != g.Charging:
+			return diff("charging", w.Charging, g.Charging)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package sim
+
+import (
+	"bytes"
+	"testing"
+)
+
+func recordTestBattle(t *testing.T, seed int64, policy string, atb bool) *BattleLog {
+	t.Helper()
+	policies, err := NewTeamPolicies(policy, policy, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log, _, err := RecordBattle([]string{"pondril", "fayluna"}, []string{"mycera", "frostnip"}, 6, 6, seed, atb, policies, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return log
+}
+
+func TestReplayRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		seed   int64
+		policy string
+		atb    bool
+	}{
+		{"utility", 7, "utility", false},
+		{"random", 11, "random", false},
+		{"focus", 3, "focus", false},
+		{"atb", 7, "utility", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := recordTestBattle(t, tt.seed, tt.policy, tt.atb)
+
+			// through the file format and back
+			var buf bytes.Buffer
+			if err := WriteBattleLog(&buf, log); err != nil {
+				t.Fatal(err)
+			}
+			read, err := ReadBattleLog(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := Replay(read); d != nil {
+				t.Fatalf("replay diverged: %v", d)
+			}
+		})
+	}
+}
+
+func TestReplayDivergence(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(log *BattleLog) int // returns the step expected to diverge
+		field string
+	}{
+		{"health", func(log *BattleLog) int {
+			log.Steps[2].State[0].Health++
+			return 2
+		}, "health"},
+		{"target out of range", func(log *BattleLog) int {
+			for i, s := range log.Steps {
+				if len(s.Targets) > 0 {
+					log.Steps[i].Targets[0] = 9
+					return i
+				}
+			}
+			t.Fatal("no step with targets")
+			return 0
+		}, "target"},
+		{"negative target", func(log *BattleLog) int {
+			for i, s := range log.Steps {
+				if len(s.Targets) > 0 {
+					log.Steps[i].Targets[0] = -1
+					return i
+				}
+			}
+			t.Fatal("no step with targets")
+			return 0
+		}, "target"},
+		{"unknown ability", func(log *BattleLog) int {
+			for i, s := range log.Steps {
+				if s.Ability != "" {
+					log.Steps[i].Ability = "no-such-ability"
+					return i
+				}
+			}
+			t.Fatal("no step with an ability")
+			return 0
+		}, "ability"},
+		{"actor", func(log *BattleLog) int {
+			log.Steps[1].Actor = 99
+			return 1
+		}, "actor"},
+		{"unknown character", func(log *BattleLog) int {
+			log.EnemyKeys[0] = "no-such-character"
+			return 0
+		}, "enemy team"},
+		{"truncated", func(log *BattleLog) int {
+			log.Steps = log.Steps[:len(log.Steps)-1]
+			return len(log.Steps)
+		}, "game over"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := recordTestBattle(t, 7, "utility", false)
+			step := tt.edit(log)
+			d := Replay(log)
+			if d == nil {
+				t.Fatal("replay reproduced an edited log")
+			}
+			if d.Field != tt.field || d.Step != step {
+				t.Errorf("diverged at step %d on %q, want step %d on %q (%v)", d.Step, d.Field, step, tt.field, d)
+			}
+		})
+	}
+}
@@ -0,0 +1,493 @@
+package sim
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Report is the flattened, serialisable view of a BatchResult.
+type Report struct {
+	Battles          int            `json:"battles"`
+	Confidence       float64        `json:"confidence"` // level of every interval below
+	AvgRoundsPerGame float64        `json:"avg_rounds_per_game"`
+	AvgRoundsCI      Interval       `json:"avg_rounds_ci"`
+	AvgTurnsPerGame  float64        `json:"avg_turns_per_game"`
+	AvgTurnsCI       Interval       `json:"avg_turns_ci"`
+	TimedOut         int            `json:"timed_out"` // battles stopped at MAX_ROUNDS
+	TimedOutRate     float64        `json:"timed_out_rate"`
+	TimedOutCI       Interval       `json:"timed_out_ci"`
+	Distributions    []SpreadRow    `json:"distributions"`
+	Histograms       Distributions  `json:"histograms"`
+	Characters       []CharacterRow `json:"characters"`
+	Teams            []TeamRow      `json:"teams"` // best win rate first
+	Matchups         []MatchupRow   `json:"matchups"`
+	Contributions    []Contribution `json:"contributions"` // best contributor first
+	Synergies        []Synergy      `json:"synergies"`     // biggest lift first
+	Abilities        []AbilityRow   `json:"abilities"`     // per character and ability
+	AbilityTotals    []AbilityRow   `json:"ability_totals"`
+	Matrix           *WinMatrix     `json:"matrix"`
+}
+
+// CharacterRow is one character's aggregate results; the damage and heal
+// columns are per battle.
+type CharacterRow struct {
+	ID          string   `json:"id"`
+	Battles     int      `json:"battles"`
+	Wins        int      `json:"wins"`
+	WinRate     float64  `json:"win_rate"`
+	WinRateCI   Interval `json:"win_rate_ci"`
+	Rating      Rating   `json:"rating"`
+	DamageDealt float64  `json:"damage_dealt"`
+	DamageTaken float64  `json:"damage_taken"`
+	HealingDone float64  `json:"healing_done"`
+	DoTDealt    float64  `json:"dot_dealt"`
+}
+
+// TeamRow is one team's results over every matchup it played, on either side,
+// and the opponent it fares worst against.
+type TeamRow struct {
+	Team           string   `json:"team"`
+	Battles        int      `json:"battles"`
+	Wins           int      `json:"wins"`
+	WinRate        float64  `json:"win_rate"`
+	WinRateCI      Interval `json:"win_rate_ci"`
+	Rating         Rating   `json:"rating"`
+	HardestCounter string   `json:"hardest_counter,omitempty"`
+	CounterWinRate float64  `json:"counter_win_rate"`
+}
+
+// SpreadRow summarises one of a batch's distributions.
+type SpreadRow struct {
+	Metric string  `json:"metric"`
+	N      int     `json:"n"`
+	Mean   float64 `json:"mean"`
+	P10    int     `json:"p10"`
+	P25    int     `json:"p25"`
+	P50    int     `json:"p50"`
+	P75    int     `json:"p75"`
+	P90    int     `json:"p90"`
+	Max    int     `json:"max"`
+}
+
+// Distribution metrics, in the order Report lists them.
+const (
+	MetricRounds    = "rounds"
+	MetricTurns     = "turns"
+	MetricFirstDown = "first_down_turn"
+	MetricWinnerHP  = "winner_hp_percent"
+)
+
+func newSpreadRow(metric string, h *Histogram) SpreadRow {
+	return SpreadRow{
+		Metric: metric,
+		N:      h.N,
+		Mean:   h.Mean(),
+		P10:    h.Percentile(0.10),
+		P25:    h.Percentile(0.25),
+		P50:    h.Percentile(0.50),
+		P75:    h.Percentile(0.75),
+		P90:    h.Percentile(0.90),
+		Max:    h.Percentile(1),
+	}
+}
+
+// MatchupRow is one matchup's results, from team A's point of view.
+type MatchupRow struct {
+	A          string   `json:"a"`
+	B          string   `json:"b"`
+	Battles    int      `json:"battles"`
+	AWins      int      `json:"a_wins"`
+	AWinRate   float64  `json:"a_win_rate"`
+	AWinRateCI Interval `json:"a_win_rate_ci"`
+	AvgRounds  float64  `json:"avg_rounds"`
+	AvgTurns   float64  `json:"avg_turns"`
+	TimedOut   int      `json:"timed_out"`
+}
+
+// AbilityRow is one ability's telemetry, for one character or, with
+// Character empty, summed over everyone who has it. MissRate is over the
+// targets an attack or debuff was aimed at; CritRate is over the hits that
+// did damage; DebuffRate is the share of debuff rolls that stuck. The
+// damage, heal, DoT and mana columns are per use.
+type AbilityRow struct {
+	Character      string  `json:"character,omitempty"`
+	Ability        string  `json:"ability"`
+	Battles        int     `json:"battles"`
+	Uses           int     `json:"uses"`
+	UsesPerBattle  float64 `json:"uses_per_battle"`
+	Misses         int     `json:"misses"`
+	ShieldAbsorbed int     `json:"shield_absorbed"`
+	MissRate       float64 `json:"miss_rate"`
+	Crits          int     `json:"crits"`
+	CritRate       float64 `json:"crit_rate"`
+	DebuffRate     float64 `json:"debuff_rate"`
+	Kills          int     `json:"kills"`
+	Damage         float64 `json:"damage"`
+	Healing        float64 `json:"healing"`
+	DoT            float64 `json:"dot"`
+	ManaSpent      float64 `json:"mana_spent"`
+	ManaGained     float64 `json:"mana_gained"`
+}
+
+// Dead reports whether the ability was available but never picked.
+func (a AbilityRow) Dead() bool {
+	return a.Battles > 0 && a.Uses == 0
+}
+
+func newAbilityRow(character, ability string, s *AbilityStats) AbilityRow {
+	// a target that got past evasion and shields either took damage or
+	// rolled for the debuff, or both
+	landed := max(s.Hits, s.DebuffsApplied+s.DebuffsResisted)
+	return AbilityRow{
+		Character:      character,
+		Ability:        ability,
+		Battles:        s.Battles,
+		Uses:           s.Uses,
+		UsesPerBattle:  ratio(float64(s.Uses), s.Battles),
+		Misses:         s.Misses,
+		ShieldAbsorbed: s.ShieldAbsorbed,
+		MissRate:       ratio(float64(s.Misses), s.Misses+s.ShieldAbsorbed+landed),
+		Crits:          s.Crits,
+		CritRate:       ratio(float64(s.Crits), s.Hits),
+		DebuffRate:     ratio(float64(s.DebuffsApplied), s.DebuffsApplied+s.DebuffsResisted),
+		Kills:          s.Kills,
+		Damage:         ratio(s.Damage, s.Uses),
+		Healing:        ratio(s.Healing, s.Uses),
+		DoT:            ratio(s.DoT, s.Uses),
+		ManaSpent:      ratio(s.ManaSpent, s.Uses),
+		ManaGained:     ratio(s.ManaGained, s.Uses),
+	}
+}
+
+// Report tables, for WriteReportCSV.
+const (
+	TableCharacters    = "characters"
+	TableTeams         = "teams"
+	TableMatchups      = "matchups"
+	TableMatrix        = "matrix"
+	TableShapley       = "shapley"
+	TableSynergy       = "synergy"
+	TableAbilities     = "abilities"
+	TableAbilityTotals = "ability_totals"
+	TableDistributions = "distributions"
+	TableHistograms    = "histograms"
+)
+
+// TeamKey is the name a team goes by in reports: its keys joined with "+".
+func TeamKey(keys []string) string {
+	return strings.Join(keys, "+")
+}
+
+// NewReport flattens res into sorted rows. Win rates get Wilson intervals;
+// the per-game averages get a bootstrap over matchups, seeded from res.Seed,
+// or with fewer than MinBootstrapClusters matchups a normal interval over the
+// individual battles. Ratings come from feeding every battle through a fresh
+// Rater.
+func NewReport(res *BatchResult) *Report {
+	rater := RateBatch(res)
+	conf := res.Confidence
+	if conf == 0 {
+		conf = DefaultConfidence
+	}
+	r := &Report{
+		Battles:          res.Battles,
+		Confidence:       conf,
+		AvgRoundsPerGame: ratio(float64(res.TotalRounds), res.Battles),
+		AvgTurnsPerGame:  ratio(float64(res.TotalTurns), res.Battles),
+		TimedOut:         res.Lengths.TimedOut,
+		TimedOutRate:     ratio(float64(res.Lengths.TimedOut), res.Battles),
+		TimedOutCI:       WilsonInterval(res.Lengths.TimedOut, res.Battles, conf),
+		Histograms:       res.Lengths,
+	}
+	for _, d := range []struct {
+		metric string
+		h      *Histogram
+	}{
+		{MetricRounds, &res.Lengths.Rounds},
+		{MetricTurns, &res.Lengths.Turns},
+		{MetricFirstDown, &res.Lengths.FirstDown},
+		{MetricWinnerHP, &res.Lengths.WinnerHP},
+	} {
+		r.Distributions = append(r.Distributions, newSpreadRow(d.metric, d.h))
+	}
+	if len(res.Matchups) < MinBootstrapClusters {
+		r.AvgRoundsCI = res.Lengths.Rounds.MeanInterval(conf)
+		r.AvgTurnsCI = res.Lengths.Turns.MeanInterval(conf)
+	} else {
+		rounds := make([]float64, len(res.Matchups))
+		turns := make([]float64, len(res.Matchups))
+		battles := make([]float64, len(res.Matchups))
+		for i, m := range res.Matchups {
+			rounds[i], turns[i], battles[i] = float64(m.Rounds), float64(m.Turns), float64(m.Battles)
+		}
+		rng := rand.New(rand.NewSource(res.Seed))
+		r.AvgRoundsCI = BootstrapRatio(rounds, battles, DefaultBootstrapSamples, conf, rng)
+		r.AvgTurnsCI = BootstrapRatio(turns, battles, DefaultBootstrapSamples, conf, rng)
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(res.Characters)) {
+		s := res.Characters[id]
+		r.Characters = append(r.Characters, CharacterRow{
+			ID:          id,
+			Battles:     s.Battles,
+			Wins:        s.Wins,
+			WinRate:     ratio(float64(s.Wins), s.Battles),
+			WinRateCI:   WilsonInterval(s.Wins, s.Battles, conf),
+			Rating:      rater.Character(id),
+			DamageDealt: ratio(s.DamageDealt, s.Battles),
+			DamageTaken: ratio(s.DamageTaken, s.Battles),
+			HealingDone: ratio(s.HealingDone, s.Battles),
+			DoTDealt:    ratio(s.DoTDealt, s.Battles),
+		})
+	}
+
+	for _, m := range res.Matchups {
+		r.Matchups = append(r.Matchups, MatchupRow{
+			A:          TeamKey(m.Matchup.A),
+			B:          TeamKey(m.Matchup.B),
+			Battles:    m.Battles,
+			AWins:      m.AWins,
+			AWinRate:   ratio(float64(m.AWins), m.Battles),
+			AWinRateCI: WilsonInterval(m.AWins, m.Battles, conf),
+			AvgRounds:  ratio(float64(m.Rounds), m.Battles),
+			AvgTurns:   ratio(float64(m.Turns), m.Battles),
+			TimedOut:   m.TimedOut,
+		})
+	}
+	r.Matrix = NewWinMatrix(res)
+	for _, st := range r.Matrix.Standings() {
+		counterRate := st.CounterWinRate
+		if math.IsNaN(counterRate) {
+			counterRate = 0
+		}
+		r.Teams = append(r.Teams, TeamRow{
+			Team:           st.Team,
+			Battles:        st.Battles,
+			Wins:           st.Wins,
+			WinRate:        st.WinRate,
+			WinRateCI:      WilsonInterval(st.Wins, st.Battles, conf),
+			Rating:         rater.Team(strings.Split(st.Team, "+")),
+			HardestCounter: st.HardestCounter,
+			CounterWinRate: counterRate,
+		})
+	}
+	r.Contributions = ShapleyContributions(res)
+	r.Synergies = PairSynergies(res, conf)
+
+	totals := map[string]*AbilityStats{}
+	keys := slices.SortedFunc(maps.Keys(res.Abilities), func(a, b AbilityKey) int {
+		if c := strings.Compare(a.Character, b.Character); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Ability, b.Ability)
+	})
+	for _, k := range keys {
+		s := res.Abilities[k]
+		r.Abilities = append(r.Abilities, newAbilityRow(k.Character, k.Ability, s))
+		t, ok := totals[k.Ability]
+		if !ok {
+			t = &AbilityStats{}
+			totals[k.Ability] = t
+		}
+		t.merge(s)
+	}
+	for _, id := range slices.Sorted(maps.Keys(totals)) {
+		r.AbilityTotals = append(r.AbilityTotals, newAbilityRow("", id, totals[id]))
+	}
+	return r
+}
+
+// WriteReportJSON writes r as one indented JSON document.
+func WriteReportJSON(w io.Writer, r *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteReportCSV writes one table of r (TableCharacters, TableTeams,
+// TableMatchups, TableMatrix, TableShapley, TableSynergy, TableAbilities,
+// TableAbilityTotals, TableDistributions or TableHistograms) as CSV with a
+// header row.
+func WriteReportCSV(w io.Writer, r *Report, table string) error {
+	header, rows, err := reportTable(r, table)
+	if err != nil {
+		return err
+	}
+	return WriteTable(w, "csv", header, rows)
+}
+
+// WriteTable writes a header row and rows as "csv" or "markdown", for
+// results that don't fit a Report.
+func WriteTable(w io.Writer, format string, header []string, rows [][]string) error {
+	switch format {
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(header)
+		cw.WriteAll(rows)
+		return cw.Error()
+	case "markdown":
+		var b strings.Builder
+		writeMarkdownTable(&b, header, rows)
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	return fmt.Errorf("unknown table format %q", format)
+}
+
+// WriteReportMarkdown writes the summary, character, team, battle length,
+// contribution and ability total tables of r as Markdown. Matchups are left
+// out; there can be hundreds of thousands.
+func WriteReportMarkdown(w io.Writer, r *Report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Simulation results\n\n")
+	fmt.Fprintf(&b, "- Battles: %d\n", r.Battles)
+	fmt.Fprintf(&b, "- Average rounds per game: %.3f (%.3f–%.3f)\n", r.AvgRoundsPerGame, r.AvgRoundsCI.Lo, r.AvgRoundsCI.Hi)
+	fmt.Fprintf(&b, "- Average turns per game: %.3f (%.3f–%.3f)\n", r.AvgTurnsPerGame, r.AvgTurnsCI.Lo, r.AvgTurnsCI.Hi)
+	fmt.Fprintf(&b, "- Battles stopped at the round limit: %d (%.2f%%)\n", r.TimedOut, 100*r.TimedOutRate)
+	fmt.Fprintf(&b, "- Intervals: %g%% confidence\n", 100*r.Confidence)
+
+	for _, t := range []struct{ title, table string }{
+		{"Characters", TableCharacters},
+		{"Teams", TableTeams},
+		{"Battle length", TableDistributions},
+		{"Contributions", TableShapley},
+		{"Abilities", TableAbilityTotals},
+	} {
+		header, rows, err := reportTable(r, t.table)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", t.title)
+		writeMarkdownTable(&b, header, rows)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// reportTable renders one table of r as strings.
+func reportTable(r *Report, table string) ([]string, [][]string, error) {
+	var rows [][]string
+	switch table {
+	case TableCharacters:
+		for _, c := range r.Characters {
+			rows = append(rows, []string{c.ID, itoa(c.Battles), itoa(c.Wins), ftoa(c.WinRate),
+				ftoa(c.WinRateCI.Lo), ftoa(c.WinRateCI.Hi), ftoa(c.Rating.Mu), ftoa(c.Rating.Sigma),
+				ftoa(c.DamageDealt), ftoa(c.DamageTaken), ftoa(c.HealingDone), ftoa(c.DoTDealt)})
+		}
+		return []string{"id", "battles", "wins", "win_rate", "win_rate_lo", "win_rate_hi", "rating_mu", "rating_sigma",
+			"damage_dealt", "damage_taken", "healing_done", "dot_dealt"}, rows, nil
+	case TableTeams:
+		for _, t := range r.Teams {
+			rows = append(rows, []string{t.Team, itoa(t.Battles), itoa(t.Wins), ftoa(t.WinRate),
+				ftoa(t.WinRateCI.Lo), ftoa(t.WinRateCI.Hi), ftoa(t.Rating.Mu), ftoa(t.Rating.Sigma), t.HardestCounter, ftoa(t.CounterWinRate)})
+		}
+		return []string{"team", "battles", "wins", "win_rate", "win_rate_lo", "win_rate_hi", "rating_mu", "rating_sigma",
+			"hardest_counter", "counter_win_rate"}, rows, nil
+	case TableMatchups:
+		for _, m := range r.Matchups {
+			rows = append(rows, []string{m.A, m.B, itoa(m.Battles), itoa(m.AWins), ftoa(m.AWinRate),
+				ftoa(m.AWinRateCI.Lo), ftoa(m.AWinRateCI.Hi), ftoa(m.AvgRounds), ftoa(m.AvgTurns), itoa(m.TimedOut)})
+		}
+		return []string{"a", "b", "battles", "a_wins", "a_win_rate", "a_win_rate_lo", "a_win_rate_hi",
+			"avg_rounds", "avg_turns", "timed_out"}, rows, nil
+	case TableMatrix:
+		// row team's win rate against column team; blank where they never met
+		if r.Matrix == nil {
+			return nil, nil, fmt.Errorf("report has no win matrix")
+		}
+		for i, team := range r.Matrix.Teams {
+			row := []string{team}
+			for _, rate := range r.Matrix.Rate[i] {
+				if math.IsNaN(rate) {
+					row = append(row, "")
+				} else {
+					row = append(row, ftoa(rate))
+				}
+			}
+			rows = append(rows, row)
+		}
+		return append([]string{"team"}, r.Matrix.Teams...), rows, nil
+	case TableShapley:
+		for _, c := range r.Contributions {
+			rows = append(rows, []string{c.ID, itoa(c.Teams), ftoa(c.WinRate), ftoa(c.Shapley),
+				ftoa(c.Teammates), strconv.FormatBool(c.Carried())})
+		}
+		return []string{"id", "teams", "win_rate", "shapley", "teammates", "carried"}, rows, nil
+	case TableAbilities, TableAbilityTotals:
+		list := r.Abilities
+		if table == TableAbilityTotals {
+			list = r.AbilityTotals
+		}
+		for _, a := range list {
+			rows = append(rows, []string{a.Character, a.Ability, itoa(a.Battles), itoa(a.Uses), ftoa(a.UsesPerBattle),
+				itoa(a.Misses), itoa(a.ShieldAbsorbed), ftoa(a.MissRate), itoa(a.Crits), ftoa(a.CritRate), ftoa(a.DebuffRate), itoa(a.Kills),
+				ftoa(a.Damage), ftoa(a.Healing), ftoa(a.DoT), ftoa(a.ManaSpent), ftoa(a.ManaGained)})
+		}
+		return []string{"character", "ability", "battles", "uses", "uses_per_battle", "misses", "shield_absorbed",
+			"miss_rate", "crits", "crit_rate", "debuff_rate", "kills", "damage_per_use", "healing_per_use", "dot_per_use",
+			"mana_spent_per_use", "mana_gained_per_use"}, rows, nil
+	case TableDistributions:
+		for _, d := range r.Distributions {
+			rows = append(rows, []string{d.Metric, itoa(d.N), ftoa(d.Mean), itoa(d.P10), itoa(d.P25),
+				itoa(d.P50), itoa(d.P75), itoa(d.P90), itoa(d.Max)})
+		}
+		return []string{"metric", "n", "mean", "p10", "p25", "p50", "p75", "p90", "max"}, rows, nil
+	case TableHistograms:
+		// one row per metric and observed value
+		for _, d := range []struct {
+			metric string
+			h      *Histogram
+		}{
+			{MetricRounds, &r.Histograms.Rounds},
+			{MetricTurns, &r.Histograms.Turns},
+			{MetricFirstDown, &r.Histograms.FirstDown},
+			{MetricWinnerHP, &r.Histograms.WinnerHP},
+		} {
+			for v, c := range d.h.Counts {
+				if c > 0 {
+					rows = append(rows, []string{d.metric, itoa(v), itoa(c)})
+				}
+			}
+		}
+		return []string{"metric", "value", "count"}, rows, nil
+	case TableSynergy:
+		for _, p := range r.Synergies {
+			rows = append(rows, []string{p.A, p.B, itoa(p.Battles), itoa(p.Wins), ftoa(p.WinRate),
+				ftoa(p.WinRateCI.Lo), ftoa(p.WinRateCI.Hi), ftoa(p.Expected), ftoa(p.Lift), strconv.FormatBool(p.Significant)})
+		}
+		return []string{"a", "b", "battles", "wins", "win_rate", "win_rate_lo", "win_rate_hi",
+			"expected", "lift", "significant"}, rows, nil
+	}
+	return nil, nil, fmt.Errorf("unknown report table %q", table)
+}
+
+func writeMarkdownTable(b *strings.Builder, header []string, rows [][]string) {
+	b.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat(" --- |", len(header)) + "\n")
+	for _, row := range rows {
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+	}
+}
+
+// ratio is num/den, or 0 when den is 0.
+func ratio(num float64, den int) float64 {
+	if den == 0 {
+		return 0
+	}
+	return num / float64(den)
+}
+
+func itoa(n int) string { return strconv.Itoa(n) }
+
+func ftoa(f float64) string { return strconv.FormatFloat(f, 'f', 4, 64) }
+
+// FormatFloat renders f the way every table cell is written: four decimals.
+func FormatFloat(f float64) string { return ftoa(f) }
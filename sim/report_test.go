@@ -0,0 +1,82 @@
+package sim
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// TestNewReportSingleMatchup checks the per-game averages of a batch too
+// small to bootstrap over matchups still get an interval from the spread of
+// its battles: five of 7 rounds and five of 8 have a standard deviation of
+// sqrt(2.5/9), so the 95% interval is 7.5 ± 1.96 × 0.527 / sqrt(10).
+func TestNewReportSingleMatchup(t *testing.T) {
+	res := &BatchResult{
+		Seed:        1,
+		Matchups:    []MatchupResult{{Matchup: Matchup{A: []string{"a"}, B: []string{"b"}}, Battles: 10, AWins: 5, Rounds: 75, Turns: 150}},
+		Battles:     10,
+		TotalRounds: 75,
+		TotalTurns:  150,
+	}
+	for range 5 {
+		for _, rounds := range []int{7, 8} {
+			res.Lengths.Rounds.Add(rounds)
+			res.Lengths.Turns.Add(2 * rounds)
+		}
+	}
+	r := NewReport(res)
+	half := zScore(0.95) * math.Sqrt(2.5/9) / math.Sqrt(10)
+	for _, tt := range []struct {
+		name string
+		got  Interval
+		mean float64
+	}{
+		{"rounds", r.AvgRoundsCI, 7.5},
+		{"turns", r.AvgTurnsCI, 15},
+	} {
+		// turns are twice the rounds, and so is their spread
+		scale := tt.mean / 7.5
+		want := Interval{tt.mean - scale*half, tt.mean + scale*half}
+		if math.Abs(tt.got.Lo-want.Lo) > 1e-9 || math.Abs(tt.got.Hi-want.Hi) > 1e-9 {
+			t.Errorf("%s interval [%g, %g], want [%g, %g]", tt.name, tt.got.Lo, tt.got.Hi, want.Lo, want.Hi)
+		}
+	}
+}
+
+func TestWinMatrixJSON(t *testing.T) {
+	res := &BatchResult{Matchups: []MatchupResult{
+		{Matchup: Matchup{A: []string{"a"}, B: []string{"b"}}, Battles: 4, AWins: 3},
+		{Matchup: Matchup{A: []string{"c"}, B: []string{"c"}}, Battles: 2, AWins: 1},
+	}}
+	data, err := json.Marshal(NewWinMatrix(res))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Teams []string     `json:"teams"`
+		Rate  [][]*float64 `json:"rate"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got.Teams, want) {
+		t.Fatalf("teams %v, want %v", got.Teams, want)
+	}
+	// teams that never met are null
+	want := [][]float64{
+		{-1, 0.75, -1},
+		{0.25, -1, -1},
+		{-1, -1, 0.5},
+	}
+	for i, row := range want {
+		for j, rate := range row {
+			switch cell := got.Rate[i][j]; {
+			case rate < 0 && cell != nil:
+				t.Errorf("rate[%d][%d] = %g, want null", i, j, *cell)
+			case rate >= 0 && (cell == nil || *cell != rate):
+				t.Errorf("rate[%d][%d] = %v, want %g", i, j, cell, rate)
+			}
+		}
+	}
+}
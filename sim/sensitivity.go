@@ -0,0 +1,139 @@
+package sim
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+)
+
+// SensitivityConfig describes a sensitivity analysis.
+type SensitivityConfig struct {
+	// Batch is played in full for the baseline and for every perturbation,
+	// once per level, always with the same seed so only the data differs.
+	// It must list its Matchups.
+	Batch  BatchConfig
+	Levels []int // Batch.AllyLevel if empty
+
+	Params []Param
+
+	// Relative, if positive, perturbs each parameter by that fraction of its
+	// value, and Absolute by that amount, e.g. 5 for ±5 power; with neither,
+	// each moves by its own Step. A whole-number field moves by at least 1.
+	Relative float64
+	Absolute float64
+
+	// Progress, if set, is called after each parameter with how many are done.
+	Progress func(done, total int)
+}
+
+// Sensitivity is how much nudging one parameter up and down moves win rates.
+//
+// Owners are the characters the parameter belongs to (see Param.Characters),
+// and OwnerDown/OwnerUp their mean win rate with the parameter lowered and
+// raised by Delta. Effect is half the difference: the owners' win rate
+// change per Delta. Meta is the root-mean-square of the same half-difference
+// over every character, owners included, and measures how much the whole
+// field shifts.
+type Sensitivity struct {
+	Param     Param    `json:"param"`
+	Value     float64  `json:"value"`
+	Delta     float64  `json:"delta"`
+	Owners    []string `json:"owners"`
+	OwnerDown float64  `json:"owner_down"`
+	OwnerUp   float64  `json:"owner_up"`
+	Effect    float64  `json:"effect"`
+	Meta      float64  `json:"meta"`
+}
+
+// Sensitivities perturbs each of cfg.Params in turn, in a clone of pack, and
+// returns them ranked by the size of their Effect, biggest first. Like
+// Balance, it installs each variant while playing it and restores the
+// previously installed data on return.
+func Sensitivities(pack *DataPack, cfg SensitivityConfig) ([]Sensitivity, error) {
+	if len(cfg.Batch.Matchups) == 0 {
+		return nil, fmt.Errorf("no matchups to evaluate")
+	}
+	if cfg.Relative > 0 && cfg.Absolute > 0 {
+		return nil, fmt.Errorf("perturb by a relative or an absolute delta, not both")
+	}
+	levels := cfg.Levels
+	if len(levels) == 0 {
+		levels = []int{cfg.Batch.AllyLevel}
+	}
+
+	prev := CurrentPack()
+	defer prev.Install()
+	work := pack.Clone()
+	work.Install()
+
+	var out []Sensitivity
+	for i, p := range cfg.Params {
+		v := p.Get(work)
+		delta := p.Step
+		switch {
+		case cfg.Relative > 0:
+			delta = math.Abs(v) * cfg.Relative
+		case cfg.Absolute > 0:
+			delta = cfg.Absolute
+		}
+		if p.Whole {
+			delta = math.Max(math.Round(delta), 1)
+		}
+		s := Sensitivity{Param: p, Value: v, Delta: delta, Owners: p.Characters(work)}
+
+		var rates [2]map[string]float64
+		for j, dir := range []float64{-1, 1} {
+			// perturbations may step past the tuning bounds, but not below
+			// zero; a mana cost can't drop to free, rounds and uses can't
+			// drop to 0, which means something else there, and a chance
+			// can't pass 100%
+			q := p
+			q.Min, q.Max = 0, math.Inf(1)
+			switch p.Field {
+			case "mana_cost", "uses", "buff.rounds", "debuff.rounds":
+				q.Min = 1
+			case "debuff.application_chance":
+				q.Max = 100
+			}
+			q.Set(work, v+dir*delta)
+			r, err := balanceWinRates(cfg.Batch, levels)
+			if err != nil {
+				return nil, err
+			}
+			rates[j] = r
+		}
+		p.Set(work, v)
+
+		owned := 0
+		for _, id := range s.Owners {
+			if _, ok := rates[0][id]; ok {
+				s.OwnerDown += rates[0][id]
+				s.OwnerUp += rates[1][id]
+				owned++
+			}
+		}
+		if owned > 0 {
+			s.OwnerDown /= float64(owned)
+			s.OwnerUp /= float64(owned)
+		}
+		s.Effect = (s.OwnerUp - s.OwnerDown) / 2
+		ids := slices.Sorted(maps.Keys(rates[0]))
+		for _, id := range ids {
+			d := (rates[1][id] - rates[0][id]) / 2
+			s.Meta += d * d
+		}
+		if len(ids) > 0 {
+			s.Meta = math.Sqrt(s.Meta / float64(len(ids)))
+		}
+		out = append(out, s)
+		if cfg.Progress != nil {
+			cfg.Progress(i+1, len(cfg.Params))
+		}
+	}
+	slices.SortStableFunc(out, func(a, b Sensitivity) int {
+		return cmp.Compare(math.Abs(b.Effect), math.Abs(a.Effect))
+	})
+	return out, nil
+}
@@ -0,0 +1,62 @@
+package sim
+
+import "testing"
+
+// TestSensitivitiesPowerSign perturbs the power of each side's only attack in
+// an even duel: more power should win its owner more games, so both effects
+// are positive, and the data installed before is back afterwards.
+func TestSensitivitiesPowerSign(t *testing.T) {
+	pack := duelPack()
+	params, err := SelectParams(pack, []string{"ability.jab.power", "ability.poke.power"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := CurrentPack()
+	for _, abs := range []float64{0, 5} {
+		res, err := Sensitivities(pack, SensitivityConfig{Batch: duelBatch(128), Params: params, Absolute: abs})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) != 2 {
+			t.Fatalf("%d sensitivities, want 2", len(res))
+		}
+		for _, s := range res {
+			want := s.Param.Step
+			if abs > 0 {
+				want = abs
+			}
+			if s.Delta != want {
+				t.Errorf("%s perturbed by %g, want %g", s.Param.Name, s.Delta, want)
+			}
+			if s.Effect <= 0 || s.OwnerUp <= s.OwnerDown {
+				t.Errorf("%s: owner win rate %g with less power and %g with more, effect %g; want it to rise",
+					s.Param.Name, s.OwnerDown, s.OwnerUp, s.Effect)
+			}
+			if s.Meta <= 0 {
+				t.Errorf("%s moved nobody's win rate", s.Param.Name)
+			}
+		}
+	}
+	if CurrentPack().Abilities["jab"] != before.Abilities["jab"] {
+		t.Error("the previously installed data wasn't put back")
+	}
+	if _, err := Sensitivities(pack, SensitivityConfig{Batch: duelBatch(8), Params: params, Relative: 0.1, Absolute: 5}); err == nil {
+		t.Error("relative and absolute deltas together accepted")
+	}
+}
+
+func TestSensitivitiesWholeDelta(t *testing.T) {
+	pack := duelPack()
+	params, err := SelectParams(pack, []string{"ability.zap.mana_cost"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a tenth of 2 mana would round back to 2
+	res, err := Sensitivities(pack, SensitivityConfig{Batch: duelBatch(8), Params: params, Relative: 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[0].Delta != 1 {
+		t.Errorf("mana cost perturbed by %g, want 1", res[0].Delta)
+	}
+}
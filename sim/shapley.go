@@ -0,0 +1,161 @@
+package sim
+
+import (
+	"cmp"
+	"math/bits"
+	"slices"
+	"strings"
+)
+
+// Contribution is one character's share of its teams' success.
+//
+// WinRate is what CharacterStats.Wins shows: the pooled win rate of every
+// team the character was on. Shapley is the character's own average marginal
+// contribution to team win probability over those teams, and Teammates is the
+// average summed contribution of the others on them. A character with a
+// decent WinRate but a negative Shapley is being carried.
+type Contribution struct {
+	ID        string  `json:"id"`
+	Teams     int     `json:"teams"`
+	WinRate   float64 `json:"win_rate"`
+	Shapley   float64 `json:"shapley"`
+	Teammates float64 `json:"teammates"`
+}
+
+// Carried reports whether c's teams do at least as well as average despite
+// c taking away from them.
+func (c Contribution) Carried() bool {
+	return c.Shapley < 0 && c.Shapley+c.Teammates >= 0
+}
+
+// ShapleyContributions estimates every character's Shapley value from a
+// batch's team results, best contributor first.
+//
+// The value of a coalition S (some members of a team) is the pooled win rate
+// of every team containing S, and the empty coalition is worth the average
+// win rate, so v(T) - v(∅) is split exactly among T's members. Teams should
+// come from a round-robin or similar, so that each subset is seen alongside
+// many different partners.
+func ShapleyContributions(res *BatchResult) []Contribution {
+	teams, coalition, all := coalitionTallies(res)
+	base := all.rate()
+	value := func(keys []string, mask int) float64 {
+		if mask == 0 {
+			return base
+		}
+		return coalition[subsetKey(keys, mask)].rate()
+	}
+
+	type sums struct {
+		teams                     int
+		shapley, teammates, value float64
+	}
+	per := map[string]*sums{}
+	for _, keys := range teams {
+		n := len(keys)
+		full := 1<<n - 1
+		phis := make([]float64, n)
+		for i := range keys {
+			bit := 1 << i
+			phi := 0.0
+			for mask := 0; mask <= full; mask++ {
+				if mask&bit != 0 {
+					continue
+				}
+				s := bits.OnesCount(uint(mask))
+				phi += shapleyWeight(s, n) * (value(keys, mask|bit) - value(keys, mask))
+			}
+			phis[i] = phi
+		}
+		// the phis add up to v(T) - v(∅)
+		total := value(keys, full) - base
+		for i, id := range keys {
+			p, ok := per[id]
+			if !ok {
+				p = &sums{}
+				per[id] = p
+			}
+			p.teams++
+			p.shapley += phis[i]
+			p.teammates += total - phis[i]
+			p.value += value(keys, 1<<i)
+		}
+	}
+
+	var out []Contribution
+	for id, p := range per {
+		out = append(out, Contribution{
+			ID:        id,
+			Teams:     p.teams,
+			WinRate:   p.value / float64(p.teams),
+			Shapley:   p.shapley / float64(p.teams),
+			Teammates: p.teammates / float64(p.teams),
+		})
+	}
+	slices.SortFunc(out, func(a, b Contribution) int {
+		if c := cmp.Compare(b.Shapley, a.Shapley); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return out
+}
+
+// tally is a pooled win count.
+type tally struct{ wins, battles int }
+
+func (t *tally) rate() float64 {
+	return ratio(float64(t.wins), t.battles)
+}
+
+// coalitionTallies pools the record of every team in res under each of its
+// non-empty subsets, keyed by subsetKey. It also returns every team's sorted
+// keys and the record of all teams together.
+func coalitionTallies(res *BatchResult) ([][]string, map[string]*tally, *tally) {
+	coalition := map[string]*tally{}
+	all := &tally{}
+	var teams [][]string
+	for _, st := range NewWinMatrix(res).Standings() {
+		keys := strings.Split(st.Team, "+")
+		slices.Sort(keys)
+		teams = append(teams, keys)
+		all.wins += st.Wins
+		all.battles += st.Battles
+		for mask := 1; mask < 1<<len(keys); mask++ {
+			k := subsetKey(keys, mask)
+			t, ok := coalition[k]
+			if !ok {
+				t = &tally{}
+				coalition[k] = t
+			}
+			t.wins += st.Wins
+			t.battles += st.Battles
+		}
+	}
+	return teams, coalition, all
+}
+
+// shapleyWeight is |S|!(n-|S|-1)!/n!, the share of orderings in which a
+// player joins right after exactly the coalition S.
+func shapleyWeight(s, n int) float64 {
+	return float64(factorial(s)*factorial(n-s-1)) / float64(factorial(n))
+}
+
+func factorial(n int) int {
+	f := 1
+	for i := 2; i <= n; i++ {
+		f *= i
+	}
+	return f
+}
+
+// subsetKey names the members of sorted keys selected by mask.
+func subsetKey(keys []string, mask int) string {
+	var sel []string
+	for i, k := range keys {
+		if mask&(1<<i) != 0 {
+			sel = append(sel, k)
+		}
+	}
+	return TeamKey(sel)
+}
@@ -0,0 +1,45 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+// TestShapleyContributions works a three-team round-robin by hand. Teams
+// ab, ac and bc finish 10/16, 4/16 and 10/16 over 8 battles each (counting
+// both sides), so v(∅) = 1/2, v(a) = v(c) = 7/16 and v(b) = 10/16. On ab,
+// a gets ½(7/16 - 8/16) + ½(10/16 - 10/16) = -1/32 and b gets 5/32; on ac
+// both get -4/32; on bc, b gets 5/32 and c -1/32. Each team's values add up
+// to v(T) - v(∅).
+func TestShapleyContributions(t *testing.T) {
+	ab, ac, bc := []string{"a", "b"}, []string{"a", "c"}, []string{"b", "c"}
+	res := &BatchResult{Matchups: []MatchupResult{
+		{Matchup: Matchup{A: ab, B: ac}, Battles: 4, AWins: 3},
+		{Matchup: Matchup{A: ab, B: bc}, Battles: 4, AWins: 2},
+		{Matchup: Matchup{A: ac, B: bc}, Battles: 4, AWins: 1},
+	}}
+	want := map[string]Contribution{
+		"a": {ID: "a", Teams: 2, WinRate: 7.0 / 16, Shapley: -5.0 / 64, Teammates: 1.0 / 64},
+		"b": {ID: "b", Teams: 2, WinRate: 10.0 / 16, Shapley: 5.0 / 32, Teammates: -1.0 / 32},
+		"c": {ID: "c", Teams: 2, WinRate: 7.0 / 16, Shapley: -5.0 / 64, Teammates: 1.0 / 64},
+	}
+	got := ShapleyContributions(res)
+	if len(got) != len(want) {
+		t.Fatalf("%d contributions, want %d", len(got), len(want))
+	}
+	if got[0].ID != "b" {
+		t.Errorf("best contributor %s, want b", got[0].ID)
+	}
+	// v(T) - v(∅) for ab, ac and bc is 1/8, -1/4 and 1/8
+	totals := map[string]float64{"a": (1.0/8 - 1.0/4) / 2, "b": 1.0 / 8, "c": (-1.0/4 + 1.0/8) / 2}
+	for _, c := range got {
+		w := want[c.ID]
+		if c.Teams != w.Teams || math.Abs(c.WinRate-w.WinRate) > 1e-12 ||
+			math.Abs(c.Shapley-w.Shapley) > 1e-12 || math.Abs(c.Teammates-w.Teammates) > 1e-12 {
+			t.Errorf("got %+v, want %+v", c, w)
+		}
+		if sum := c.Shapley + c.Teammates; math.Abs(sum-totals[c.ID]) > 1e-12 {
+			t.Errorf("%s: own and teammates' shares add up to %g, want v(T) - v(∅) = %g on average", c.ID, sum, totals[c.ID])
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package sim
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+)
+
+// DefaultJumpThreshold is the win-rate change between neighbouring levels
+// that SweepLevels flags when none is configured.
+const DefaultJumpThreshold = 0.05
+
+// SweepConfig describes a level sweep.
+type SweepConfig struct {
+	// Batch is played once per level; its AllyLevel and EnemyLevel are
+	// replaced. It must list its Matchups.
+	Batch BatchConfig
+
+	Levels []int // ally levels, in the order to play them
+
+	// EnemyOffset sets the enemy level to each ally level plus the offset,
+	// for story encounters fought over or under level.
+	EnemyOffset int
+
+	// JumpThreshold is the change in win rate between neighbouring levels
+	// worth flagging; 0 means DefaultJumpThreshold.
+	JumpThreshold float64
+
+	// Progress, if set, is called after each level with how many are done.
+	Progress func(done, total int)
+}
+
+// SweepPoint is one character's results at one level.
+//
+// WinRate is taken over both sides when allies and enemies share a level.
+// When they don't, it is the ally-side win rate alone: that is the player's
+// experience, and pooling would mix two different levels.
+type SweepPoint struct {
+	Battles   int      `json:"battles"`
+	WinRate   float64  `json:"win_rate"`
+	WinRateCI Interval `json:"win_rate_ci"`
+	Rating    Rating   `json:"rating"`
+	Unlocked  []string `json:"unlocked,omitempty"` // abilities gained since the previous level
+}
+
+// SweepLevel is every character's results at one level.
+type SweepLevel struct {
+	AllyLevel  int                   `json:"ally_level"`
+	EnemyLevel int                   `json:"enemy_level"`
+	Battles    int                   `json:"battles"`
+	Characters map[string]SweepPoint `json:"characters"`
+}
+
+// SweepJump is a win-rate change between neighbouring levels of at least the
+// threshold. Unlocked lists the abilities the character gained in between;
+// when it is empty the jump comes from stat growth or from what the rest of
+// the roster unlocked.
+//
+// ChangeCI combines the two levels' win-rate intervals into one for the
+// change (Newcombe's method), and Significant is set when it leaves out
+// zero: a smaller change could be noise, however large it looks.
+type SweepJump struct {
+	ID          string   `json:"id"`
+	From        int      `json:"from"`
+	To          int      `json:"to"`
+	Change      float64  `json:"change"`
+	ChangeCI    Interval `json:"change_ci"`
+	Significant bool     `json:"significant"`
+	Unlocked    []string `json:"unlocked,omitempty"`
+}
+
+// SweepResult is the outcome of SweepLevels.
+type SweepResult struct {
+	Levels []SweepLevel `json:"levels"`
+	Jumps  []SweepJump  `json:"jumps"` // by level, then character
+}
+
+// SweepLevels plays cfg.Batch at every level of cfg.Levels and charts each
+// character's win rate and rating along the way, flagging the jumps.
+func SweepLevels(cfg SweepConfig) (*SweepResult, error) {
+	if len(cfg.Levels) == 0 {
+		return nil, fmt.Errorf("no levels to sweep")
+	}
+	if len(cfg.Batch.Matchups) == 0 {
+		return nil, fmt.Errorf("no matchups to play")
+	}
+	threshold := cfg.JumpThreshold
+	if threshold <= 0 {
+		threshold = DefaultJumpThreshold
+	}
+	conf := cfg.Batch.Confidence
+	if conf == 0 {
+		conf = DefaultConfidence
+	}
+
+	out := &SweepResult{}
+	for i, lv := range cfg.Levels {
+		batch := cfg.Batch
+		batch.AllyLevel, batch.EnemyLevel = lv, lv+cfg.EnemyOffset
+		res, err := RunBatch(batch)
+		if err != nil {
+			return nil, err
+		}
+		rater := RateBatch(res)
+		sl := SweepLevel{
+			AllyLevel:  batch.AllyLevel,
+			EnemyLevel: batch.EnemyLevel,
+			Battles:    res.Battles,
+			Characters: map[string]SweepPoint{},
+		}
+		for id, s := range res.Characters {
+			wins, battles := s.Wins, s.Battles
+			if cfg.EnemyOffset != 0 {
+				wins, battles = s.AllyWins, s.AllyBattles
+			}
+			pt := SweepPoint{
+				Battles:   battles,
+				WinRate:   ratio(float64(wins), battles),
+				WinRateCI: WilsonInterval(wins, battles, conf),
+				Rating:    rater.Character(id),
+			}
+			if i > 0 {
+				pt.Unlocked = unlockedBetween(id, cfg.Levels[i-1], lv)
+			}
+			sl.Characters[id] = pt
+		}
+		out.Levels = append(out.Levels, sl)
+		if cfg.Progress != nil {
+			cfg.Progress(i+1, len(cfg.Levels))
+		}
+	}
+
+	for i := 1; i < len(out.Levels); i++ {
+		prev, cur := out.Levels[i-1], out.Levels[i]
+		for _, id := range slices.Sorted(maps.Keys(cur.Characters)) {
+			before, ok := prev.Characters[id]
+			if !ok || before.Battles == 0 || cur.Characters[id].Battles == 0 {
+				continue
+			}
+			after := cur.Characters[id]
+			change := after.WinRate - before.WinRate
+			if math.Abs(change) >= threshold {
+				ci := changeInterval(before, after)
+				out.Jumps = append(out.Jumps, SweepJump{
+					ID:          id,
+					From:        prev.AllyLevel,
+					To:          cur.AllyLevel,
+					Change:      change,
+					ChangeCI:    ci,
+					Significant: ci.Lo > 0 || ci.Hi < 0,
+					Unlocked:    after.Unlocked,
+				})
+			}
+		}
+	}
+	return out, nil
+}
+
+// changeInterval is the interval for after.WinRate - before.WinRate built
+// from the two win-rate intervals: each end adds, in quadrature, how far the
+// two bounds that push the difference that way lie from their rates.
+func changeInterval(before, after SweepPoint) Interval {
+	change := after.WinRate - before.WinRate
+	down := math.Hypot(after.WinRate-after.WinRateCI.Lo, before.WinRateCI.Hi-before.WinRate)
+	up := math.Hypot(after.WinRateCI.Hi-after.WinRate, before.WinRate-before.WinRateCI.Lo)
+	return Interval{change - down, change + up}
+}
+
+// unlockedBetween lists the abilities id gains going from level from to
+// level to, in template order.
+func unlockedBetween(id string, from, to int) []string {
+	var out []string
+	for _, at := range CharacterTemplates[id].AbilityTemplates {
+		if at.MinLv > from && at.MinLv <= to {
+			out = append(out, at.Key)
+		}
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+package sim
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestUnlockedBetween(t *testing.T) {
+	withPack(t, duelPack())
+	tests := []struct {
+		from, to int
+		want     []string
+	}{
+		{2, 3, nil},
+		{3, 4, []string{"zap"}},
+		{2, 6, []string{"zap"}},
+		{4, 6, nil},
+	}
+	for _, tt := range tests {
+		if got := unlockedBetween("hero", tt.from, tt.to); !slices.Equal(got, tt.want) {
+			t.Errorf("hero from level %d to %d unlocks %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+// TestSweepLevelsUnlocks sweeps the duel over levels 2, 4 and 6 with every
+// change flagged: hero's zap arrives between 2 and 4 and nowhere else, and
+// foe never unlocks anything.
+func TestSweepLevelsUnlocks(t *testing.T) {
+	withPack(t, duelPack())
+	res, err := SweepLevels(SweepConfig{Batch: duelBatch(64), Levels: []int{2, 4, 6}, JumpThreshold: 1e-9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Levels) != 3 {
+		t.Fatalf("%d levels, want 3", len(res.Levels))
+	}
+	for i, want := range [][]string{nil, {"zap"}, nil} {
+		l := res.Levels[i]
+		if got := l.Characters["hero"].Unlocked; !slices.Equal(got, want) {
+			t.Errorf("level %d: hero unlocked %v, want %v", l.AllyLevel, got, want)
+		}
+		if got := l.Characters["foe"].Unlocked; got != nil {
+			t.Errorf("level %d: foe unlocked %v, want nothing", l.AllyLevel, got)
+		}
+	}
+	if len(res.Jumps) == 0 {
+		t.Fatal("no jumps flagged")
+	}
+	for _, j := range res.Jumps {
+		want := []string(nil)
+		if j.ID == "hero" && j.To == 4 {
+			want = []string{"zap"}
+		}
+		if !slices.Equal(j.Unlocked, want) {
+			t.Errorf("%s's jump from %d to %d credited to %v, want %v", j.ID, j.From, j.To, j.Unlocked, want)
+		}
+	}
+}
+
+func TestChangeInterval(t *testing.T) {
+	point := func(rate, lo, hi float64) SweepPoint {
+		return SweepPoint{WinRate: rate, WinRateCI: Interval{lo, hi}}
+	}
+	tests := []struct {
+		name          string
+		before, after SweepPoint
+		want          Interval
+	}{
+		// 0.2 - hypot(0.08, 0.1) and 0.2 + hypot(0.06, 0.1)
+		{"clear rise", point(0.5, 0.4, 0.6), point(0.7, 0.62, 0.76), Interval{0.2 - 0.128062, 0.2 + 0.116619}},
+		// a 5-point change inside ±10-point intervals could be noise
+		{"within the noise", point(0.5, 0.4, 0.6), point(0.55, 0.45, 0.65), Interval{0.05 - 0.141421, 0.05 + 0.141421}},
+		{"fall", point(0.7, 0.62, 0.76), point(0.5, 0.4, 0.6), Interval{-0.2 - 0.116619, -0.2 + 0.128062}},
+	}
+	for _, tt := range tests {
+		got := changeInterval(tt.before, tt.after)
+		if math.Abs(got.Lo-tt.want.Lo) > 1e-6 || math.Abs(got.Hi-tt.want.Hi) > 1e-6 {
+			t.Errorf("%s: [%.6f, %.6f], want [%.6f, %.6f]", tt.name, got.Lo, got.Hi, tt.want.Lo, tt.want.Hi)
+		}
+	}
+}
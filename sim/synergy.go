@@ -0,0 +1,82 @@
+package sim
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Synergy compares how a pair of characters does on the same team with what
+// their separate strengths predict.
+//
+// WinRate is the pooled win rate of every team holding both A and B.
+// Expected adds the two characters' individual strengths on the log-odds
+// scale, each measured as its own teams' win rate against the average. Lift
+// is WinRate - Expected: a clearly positive Lift is a combo, a negative one a
+// pair that gets in each other's way. Significant is set when Expected falls
+// outside WinRateCI.
+type Synergy struct {
+	A           string   `json:"a"`
+	B           string   `json:"b"`
+	Battles     int      `json:"battles"`
+	Wins        int      `json:"wins"`
+	WinRate     float64  `json:"win_rate"`
+	WinRateCI   Interval `json:"win_rate_ci"`
+	Expected    float64  `json:"expected"`
+	Lift        float64  `json:"lift"`
+	Significant bool     `json:"significant"`
+}
+
+// PairSynergies returns every pair of characters that shared a team in res,
+// biggest Lift first. Like ShapleyContributions it wants each pair seen with
+// a variety of third members and opponents, as a round-robin provides.
+func PairSynergies(res *BatchResult, confidence float64) []Synergy {
+	_, coalition, all := coalitionTallies(res)
+	base := logit(all.rate())
+
+	var out []Synergy
+	for key, t := range coalition {
+		pair := strings.Split(key, "+")
+		if len(pair) != 2 {
+			continue
+		}
+		strength := func(id string) float64 {
+			s := coalition[id]
+			// half a win of smoothing keeps a perfect record finite
+			return logit((float64(s.wins)+0.5)/(float64(s.battles)+1)) - base
+		}
+		expected := sigmoid(base + strength(pair[0]) + strength(pair[1]))
+		s := Synergy{
+			A:         pair[0],
+			B:         pair[1],
+			Battles:   t.battles,
+			Wins:      t.wins,
+			WinRate:   t.rate(),
+			WinRateCI: WilsonInterval(t.wins, t.battles, confidence),
+			Expected:  expected,
+		}
+		s.Lift = s.WinRate - s.Expected
+		s.Significant = expected < s.WinRateCI.Lo || expected > s.WinRateCI.Hi
+		out = append(out, s)
+	}
+	slices.SortFunc(out, func(a, b Synergy) int {
+		if c := cmp.Compare(b.Lift, a.Lift); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.A, b.A); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.B, b.B)
+	})
+	return out
+}
+
+func logit(p float64) float64 {
+	p = min(max(p, 1e-9), 1-1e-9)
+	return math.Log(p / (1 - p))
+}
+
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
@@ -0,0 +1,35 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+// TestPairSynergiesAdditive builds a pair whose record is exactly what its
+// members' strengths predict. With the half-win smoothing a goes 6/8, so
+// 6.5/9, and b goes 2/8, so 2.5/9: equal and opposite log-odds around the
+// 50% base. Together they win half their games, which is what adding those
+// strengths predicts, so the pair shows no synergy either way.
+func TestPairSynergiesAdditive(t *testing.T) {
+	ab, ac, bd := []string{"a", "b"}, []string{"a", "c"}, []string{"b", "d"}
+	res := &BatchResult{Matchups: []MatchupResult{
+		{Matchup: Matchup{A: ab, B: ab}, Battles: 2, AWins: 1},
+		{Matchup: Matchup{A: ac, B: bd}, Battles: 4, AWins: 4},
+	}}
+	for _, s := range PairSynergies(res, 0.95) {
+		if s.A != "a" || s.B != "b" {
+			continue
+		}
+		if s.Battles != 4 || s.Wins != 2 {
+			t.Errorf("pair record %d/%d, want 2/4", s.Wins, s.Battles)
+		}
+		if math.Abs(s.Expected-0.5) > 1e-12 || math.Abs(s.Lift) > 1e-12 {
+			t.Errorf("expected %g with lift %g, want 0.5 and no lift", s.Expected, s.Lift)
+		}
+		if s.Significant {
+			t.Error("an additive pair flagged as significant")
+		}
+		return
+	}
+	t.Fatal("no synergy reported for a + b")
+}
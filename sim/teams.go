@@ -0,0 +1,129 @@
+package sim
+
+import (
+	"cmp"
+	"encoding/json"
+	"math"
+	"slices"
+)
+
+// WinMatrix holds every team's win rate against every other team.
+// Rate[i][j] is Teams[i]'s win rate against Teams[j], or NaN if the two
+// never met.
+type WinMatrix struct {
+	Teams   []string    `json:"teams"`
+	Wins    [][]int     `json:"wins"`
+	Battles [][]int     `json:"battles"`
+	Rate    [][]float64 `json:"rate"`
+}
+
+// MarshalJSON writes the matrix with null for the rate of teams that never
+// met, which JSON has no NaN for.
+func (mx WinMatrix) MarshalJSON() ([]byte, error) {
+	rate := make([][]*float64, len(mx.Rate))
+	for i, row := range mx.Rate {
+		rate[i] = make([]*float64, len(row))
+		for j := range row {
+			if !math.IsNaN(row[j]) {
+				rate[i][j] = &row[j]
+			}
+		}
+	}
+	type plain WinMatrix // without the method, so it doesn't recurse
+	return json.Marshal(struct {
+		plain
+		Rate [][]*float64 `json:"rate"`
+	}{plain(mx), rate})
+}
+
+// TeamStanding is one team's overall record and the opponent it does worst
+// against.
+type TeamStanding struct {
+	Team           string
+	Battles        int
+	Wins           int
+	WinRate        float64
+	HardestCounter string  // empty if the team never met anyone but itself
+	CounterWinRate float64 // Team's win rate against HardestCounter
+}
+
+// NewWinMatrix builds the team-vs-team matrix from a batch. A matchup counts
+// for both teams, from each one's side.
+func NewWinMatrix(res *BatchResult) *WinMatrix {
+	index := map[string]int{}
+	var teams []string
+	for _, m := range res.Matchups {
+		for _, keys := range [][]string{m.Matchup.A, m.Matchup.B} {
+			k := TeamKey(keys)
+			if _, ok := index[k]; !ok {
+				index[k] = 0
+				teams = append(teams, k)
+			}
+		}
+	}
+	slices.Sort(teams)
+	for i, k := range teams {
+		index[k] = i
+	}
+
+	n := len(teams)
+	mx := &WinMatrix{
+		Teams:   teams,
+		Wins:    make([][]int, n),
+		Battles: make([][]int, n),
+		Rate:    make([][]float64, n),
+	}
+	for i := range teams {
+		mx.Wins[i] = make([]int, n)
+		mx.Battles[i] = make([]int, n)
+		mx.Rate[i] = make([]float64, n)
+	}
+	for _, m := range res.Matchups {
+		a, b := index[TeamKey(m.Matchup.A)], index[TeamKey(m.Matchup.B)]
+		mx.Wins[a][b] += m.AWins
+		mx.Battles[a][b] += m.Battles
+		if a != b {
+			mx.Wins[b][a] += m.Battles - m.AWins
+			mx.Battles[b][a] += m.Battles
+		}
+	}
+	for i := range teams {
+		for j := range teams {
+			if mx.Battles[i][j] == 0 {
+				mx.Rate[i][j] = math.NaN()
+			} else {
+				mx.Rate[i][j] = float64(mx.Wins[i][j]) / float64(mx.Battles[i][j])
+			}
+		}
+	}
+	return mx
+}
+
+// Standings returns every team's record, best win rate first. A mirror match
+// counts once per side, so it adds one win and one loss.
+func (mx *WinMatrix) Standings() []TeamStanding {
+	out := make([]TeamStanding, len(mx.Teams))
+	for i, team := range mx.Teams {
+		s := TeamStanding{Team: team, CounterWinRate: math.NaN()}
+		for j := range mx.Teams {
+			battles, wins := mx.Battles[i][j], mx.Wins[i][j]
+			if i == j {
+				// both sides of a mirror are this team
+				battles, wins = 2*battles, battles
+			}
+			s.Battles += battles
+			s.Wins += wins
+			if i != j && mx.Battles[i][j] > 0 &&
+				(s.HardestCounter == "" || mx.Rate[i][j] < s.CounterWinRate) {
+				s.HardestCounter = mx.Teams[j]
+				s.CounterWinRate = mx.Rate[i][j]
+			}
+		}
+		s.WinRate = ratio(float64(s.Wins), s.Battles)
+		out[i] = s
+	}
+	slices.SortStableFunc(out, func(a, b TeamStanding) int {
+		return cmp.Compare(b.WinRate, a.WinRate)
+	})
+	return out
+}
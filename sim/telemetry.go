@@ -0,0 +1,116 @@
+package sim
+
+// AbilityKey identifies one character's use of one ability.
+type AbilityKey struct {
+	Character string
+	Ability   string
+}
+
+// AbilityStats accumulates what one ability did for one character over many
+// battles. Battles counts the battles the character had the ability in, used
+// or not, so abilities that are never picked still show up.
+type AbilityStats struct {
+	Battles         int
+	Uses            int
+	Misses          int
+	ShieldAbsorbed  int
+	Hits            int // targets that took damage
+	Crits           int // of those hits, the critical ones
+	Kills           int
+	BuffsApplied    int
+	DebuffsApplied  int
+	DebuffsResisted int
+	Damage          float64
+	Healing         float64
+	DoT             float64 // damage from the ability's debuff ticking later
+	ManaSpent       float64
+	ManaGained      float64
+}
+
+func (s *AbilityStats) merge(o *AbilityStats) {
+	s.Battles += o.Battles
+	s.Uses += o.Uses
+	s.Misses += o.Misses
+	s.ShieldAbsorbed += o.ShieldAbsorbed
+	s.Hits += o.Hits
+	s.Crits += o.Crits
+	s.Kills += o.Kills
+	s.BuffsApplied += o.BuffsApplied
+	s.DebuffsApplied += o.DebuffsApplied
+	s.DebuffsResisted += o.DebuffsResisted
+	s.Damage += o.Damage
+	s.Healing += o.Healing
+	s.DoT += o.DoT
+	s.ManaSpent += o.ManaSpent
+	s.ManaGained += o.ManaGained
+}
+
+// Telemetry is an EventSink that tallies AbilityStats from a battle's
+// events. One Telemetry can watch any number of battles in turn.
+type Telemetry map[AbilityKey]*AbilityStats
+
+// Join counts a battle for every ability the characters bring to it. Call it
+// once per battle, before the first event.
+func (t Telemetry) Join(team []*Character) {
+	for _, c := range team {
+		for _, ab := range c.Abilities {
+			t.stats(c.ID, ab.ID).Battles++
+		}
+	}
+}
+
+func (t Telemetry) Emit(ev Event) {
+	if ev.Actor == "" || ev.Ability == "" {
+		return
+	}
+	s := t.stats(ev.Actor, ev.Ability)
+	switch ev.Type {
+	case EventChargeStarted:
+		// counted with the mana it costs, so a charge that fizzles still
+		// shows up as a use
+		s.Uses++
+	case EventAbilityUsed:
+		if !ev.Charged {
+			s.Uses++
+		}
+	case EventManaChanged:
+		if ev.Amount < 0 {
+			s.ManaSpent -= ev.Amount
+		} else {
+			s.ManaGained += ev.Amount
+		}
+	case EventMissed:
+		s.Misses++
+	case EventShieldAbsorbed:
+		s.ShieldAbsorbed++
+	case EventDamage:
+		s.Hits++
+		if ev.Crit {
+			s.Crits++
+		}
+		s.Damage += ev.Amount
+	case EventHeal:
+		s.Healing += ev.Amount
+	case EventBuffApplied:
+		s.BuffsApplied++
+	case EventDebuffApplied, EventDebuffRefreshed, EventElementUnchanged:
+		// every roll that stuck, whether or not it changed anything
+		s.DebuffsApplied++
+	case EventDebuffResisted:
+		s.DebuffsResisted++
+	case EventDoTTick:
+		s.DoT += ev.Amount
+	case EventCharacterDown:
+		s.Kills++
+	}
+}
+
+func (t Telemetry) stats(character, ability string) *AbilityStats {
+	key := AbilityKey{character, ability}
+	s, ok := t[key]
+	if !ok {
+		s = &AbilityStats{}
+		t[key] = s
+	}
+	return s
+}
@@ -0,0 +1,181 @@
+package sim
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// fighter is a plain level 5 character with no elements, evasion or mana,
+// so a battle between fighters rolls nothing but what the test sets up.
+func fighter(id string, isAlly bool, speed float64) *Character {
+	return &Character{
+		ID:        id,
+		IsAlly:    isAlly,
+		Level:     5,
+		Health:    100,
+		MaxHealth: 100,
+		MaxMana:   10,
+		Strength:  10,
+		Defense:   10,
+		Spirit:    50,
+		Speed:     speed,
+	}
+}
+
+// move is one scripted turn: an ability and the ID of its target. The zero
+// move passes.
+type move struct {
+	ability *Ability
+	target  string
+}
+
+// scripted plays moves[id][n-1] on character id's nth turn, counting lost
+// turns, and passes once the script runs out.
+func scripted(moves map[string][]move) DecisionFunc {
+	return func(actor *Character, allies, enemies []*Character, rng *rand.Rand) (*Ability, []*Character) {
+		plan := moves[actor.ID]
+		if actor.Turns > len(plan) || plan[actor.Turns-1].ability == nil {
+			return nil, nil
+		}
+		m := plan[actor.Turns-1]
+		for _, c := range slices.Concat(allies, enemies) {
+			if c.ID == m.target {
+				return m.ability, []*Character{c}
+			}
+		}
+		return nil, nil
+	}
+}
+
+// playOut steps e to the end of the battle.
+func playOut(e *Engine, policy Policy) {
+	for !e.GameOver {
+		e.Step(policy)
+	}
+}
+
+var (
+	testBash = &Ability{ID: "bash", Type: "attack", TargetType: "single", TargetSelectType: "enemy", Power: 30}
+	testHeal = &Ability{ID: "mend", Type: "heal", TargetType: "single", TargetSelectType: "ally", Power: 100}
+)
+
+func TestTelemetryDoTKill(t *testing.T) {
+	venom := &Ability{ID: "venom", Type: "debuff", TargetType: "single", TargetSelectType: "enemy",
+		Debuff: &Debuff{Type: "poison", Rounds: 3, DamagePercent: 60, ApplicationChance: 100}}
+	ally, enemy := fighter("a", true, 10), fighter("e", false, 5)
+	e := NewEngine([]*Character{ally}, []*Character{enemy}, 1)
+	tel := Telemetry{}
+	var rec EventRecorder
+	e.Events = MultiSink{tel, &rec}
+	playOut(e, scripted(map[string][]move{"a": {{venom, "e"}}}))
+
+	if !e.PlayerWon {
+		t.Fatal("the poison didn't win the battle")
+	}
+	i := slices.IndexFunc(rec.Events, func(ev Event) bool { return ev.Type == EventCharacterDown })
+	if down := rec.Events[i]; down.Actor != "a" || !down.ActorIsAlly || down.Ability != "venom" {
+		t.Errorf("kill credited to %q (ally %v) with %q, want a's venom", down.Actor, down.ActorIsAlly, down.Ability)
+	}
+	if k := tel.stats("a", "venom").Kills; k != 1 {
+		t.Errorf("venom kills = %d, want 1", k)
+	}
+}
+
+// TestDoTSide checks DoT ticks and kills name the side of whoever applied the
+// debuff, which is not always the victim's enemy: a debuff that can target
+// anyone may land on a teammate.
+func TestDoTSide(t *testing.T) {
+	venom := &Ability{ID: "venom", Type: "debuff", TargetType: "single", TargetSelectType: "any",
+		Debuff: &Debuff{Type: "poison", Rounds: 3, DamagePercent: 60, ApplicationChance: 100}}
+	for _, victim := range []string{"e", "a2"} {
+		t.Run(victim, func(t *testing.T) {
+			caster, mate, enemy := fighter("a1", true, 10), fighter("a2", true, 5), fighter("e", false, 1)
+			e := NewEngine([]*Character{caster, mate}, []*Character{enemy}, 1)
+			var rec EventRecorder
+			e.Events = &rec
+			policy := scripted(map[string][]move{"a1": {{venom, victim}}})
+			for !slices.ContainsFunc(rec.Events, func(ev Event) bool { return ev.Type == EventCharacterDown }) {
+				if e.TotalRounds > 5 {
+					t.Fatal("the poison never killed anyone")
+				}
+				e.Step(policy)
+			}
+			for _, ev := range rec.Events {
+				if ev.Type != EventDoTTick && ev.Type != EventCharacterDown {
+					continue
+				}
+				if ev.Actor != "a1" || !ev.ActorIsAlly || ev.Target != victim {
+					t.Errorf("%s on %s credited to %q (ally %v), want a1 on the ally side", ev.Type, ev.Target, ev.Actor, ev.ActorIsAlly)
+				}
+			}
+		})
+	}
+}
+
+func TestTelemetryChargeUses(t *testing.T) {
+	meteor := &Ability{ID: "meteor", Type: "attack", TargetType: "single", TargetSelectType: "enemy",
+		Power: 30, ManaCost: 4, ChargeTurns: 1}
+	tests := []struct {
+		name   string
+		finish bool // whether the other ally finishes off the charge's target first
+	}{
+		{"lands", false},
+		{"fizzles", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caster, helper := fighter("a1", true, 10), fighter("a2", true, 5)
+			caster.Mana = 10
+			target, other := fighter("e1", false, 1), fighter("e2", false, 1)
+			target.Health = 1
+			moves := map[string][]move{"a1": {{meteor, "e1"}}}
+			if tt.finish {
+				moves["a2"] = []move{{testBash, "e1"}}
+			}
+			e := NewEngine([]*Character{caster, helper}, []*Character{target, other}, 1)
+			tel := Telemetry{}
+			e.Events = tel
+			playOut(e, scripted(moves))
+
+			s := tel.stats("a1", "meteor")
+			if s.Uses != 1 || s.ManaSpent != 4 {
+				t.Errorf("uses %d, mana spent %g; want 1 use for 4 mana", s.Uses, s.ManaSpent)
+			}
+			if landed := s.Hits > 0; landed == tt.finish {
+				t.Errorf("hits = %d with the target finished off first: %v", s.Hits, tt.finish)
+			}
+		})
+	}
+}
+
+func TestTelemetryHealingCapped(t *testing.T) {
+	tests := []struct {
+		name   string
+		health float64
+		want   float64
+	}{
+		{"missing 10", 90, 10},
+		{"full", 100, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healer, hurt := fighter("healer", true, 10), fighter("hurt", true, 5)
+			hurt.Health = tt.health
+			e := NewEngine([]*Character{healer, hurt}, []*Character{fighter("e", false, 1)}, 1)
+			tel := Telemetry{}
+			e.Events = tel
+			e.Step(scripted(map[string][]move{"healer": {{testHeal, "hurt"}}}))
+
+			if hurt.Health != 100 {
+				t.Errorf("health %g after the heal, want 100", hurt.Health)
+			}
+			if got := tel.stats("healer", "mend").Healing; got != tt.want {
+				t.Errorf("telemetry healing = %g, want %g", got, tt.want)
+			}
+			if got := -e.LastImpacts[0].Delta; got != tt.want {
+				t.Errorf("impact healing = %g, want %g", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package sim
+
+import (
+	"math"
+	"sort"
+)
+
+// EffectiveSpeed is c's Speed under its active speed buffs and debuffs, which
+// move it by at most 50% either way, like strength and defense.
+func EffectiveSpeed(c *Character) float64 {
+	return c.Speed * GetEffectiveModifierForAttack(c, "speed")
+}
+
+// UseATB switches e from one turn each per round to an initiative timeline:
+// at the start of every round each character gains its effective speed in
+// initiative, and acts once for every time its initiative reaches that of
+// the slowest character standing, the most initiative first. The slowest
+// acts once a round; one twice as fast acts twice, and leftover initiative
+// carries into the next round. Call it before the first Step.
+func (e *Engine) UseATB() {
+	e.ATB = true
+	e.initiative = make([]float64, len(e.Characters))
+	e.planRound()
+}
+
+// planRound rebuilds TurnOrder for the round about to start from everyone
+// still standing, by effective speed. Ties go to a per-character draw from
+// the engine's seed, reshuffled every round, so they don't always favour the
+// same character and cost no rolls from Rand.
+func (e *Engine) planRound() {
+	live := e.TurnOrder[:0]
+	for i, c := range e.Characters {
+		if c.Health > 0 {
+			live = append(live, i)
+		}
+	}
+	speed := make([]float64, len(e.Characters))
+	for _, i := range live {
+		speed[i] = EffectiveSpeed(e.Characters[i])
+	}
+
+	if !e.ATB {
+		sort.SliceStable(live, func(a, b int) bool {
+			return e.actsBefore(live[a], live[b], speed)
+		})
+		e.TurnOrder = live
+		return
+	}
+
+	// a character with no speed still creeps along the timeline
+	threshold := math.Inf(1)
+	for _, i := range live {
+		speed[i] = max(speed[i], 1)
+		threshold = min(threshold, speed[i])
+		e.initiative[i] += speed[i]
+	}
+	order := make([]int, 0, 2*len(live))
+	for {
+		next := -1
+		for _, i := range live {
+			if e.initiative[i] >= threshold && (next < 0 || e.actsBefore(i, next, e.initiative)) {
+				next = i
+			}
+		}
+		if next < 0 {
+			break
+		}
+		order = append(order, next)
+		e.initiative[next] -= threshold
+	}
+	e.TurnOrder = order
+}
+
+// actsBefore reports whether character i goes before character j, given
+// their key: speed, or initiative in ATB mode.
+func (e *Engine) actsBefore(i, j int, key []float64) bool {
+	if key[i] != key[j] {
+		return key[i] > key[j]
+	}
+	round := uint64(e.TotalRounds)
+	return splitmix64(uint64(e.tiebreak[i])^round) < splitmix64(uint64(e.tiebreak[j])^round)
+}
@@ -0,0 +1,127 @@
+package sim
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// turnIDs names the characters of e's TurnOrder, in order.
+func turnIDs(e *Engine) []string {
+	ids := make([]string, len(e.TurnOrder))
+	for i, idx := range e.TurnOrder {
+		ids[i] = e.Characters[idx].ID
+	}
+	return ids
+}
+
+func TestTurnOrderBySpeed(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(a, b, c *Character)
+		want  []string
+	}{
+		{"fastest first", func(a, b, c *Character) {}, []string{"b", "c", "a"}},
+		{"speed buff", func(a, b, c *Character) {
+			a.ActiveBuffs = append(a.ActiveBuffs, BuffInstance{Stat: "speed", ModifierPct: 50, TotalRounds: 3})
+		}, []string{"b", "a", "c"}},
+		{"speed debuff", func(a, b, c *Character) {
+			c.ActiveDebuffs = append(c.ActiveDebuffs, DebuffInstance{Stat: "speed", ModifierPct: 50, TotalRounds: 3})
+		}, []string{"b", "a", "c"}},
+		{"dead left out", func(a, b, c *Character) { b.Health = 0 }, []string{"c", "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b, c := fighter("a", true, 4), fighter("b", true, 9), fighter("c", false, 5)
+			tt.setup(a, b, c)
+			e := NewEngine([]*Character{a, b}, []*Character{c}, 1)
+			if got := turnIDs(e); !slices.Equal(got, tt.want) {
+				t.Errorf("turn order %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTurnOrderSpeedBuffNextRound(t *testing.T) {
+	haste := &Ability{ID: "haste", Type: "buff", TargetType: "single", TargetSelectType: "ally",
+		Buff: &Buff{Type: "speed", Rounds: 3, ModifierPercent: 40}}
+	fast, slow := fighter("fast", true, 10), fighter("slow", true, 8)
+	e := NewEngine([]*Character{fast, slow}, []*Character{fighter("e", false, 1)}, 1)
+	policy := scripted(map[string][]move{"slow": {{haste, "slow"}}})
+
+	// the buff lands mid-round, and the order holds until the round is over
+	for e.TotalRounds == 0 {
+		if got := turnIDs(e); !slices.Equal(got, []string{"fast", "slow", "e"}) {
+			t.Fatalf("round 1 order %v", got)
+		}
+		e.Step(policy)
+	}
+	if got := turnIDs(e); !slices.Equal(got, []string{"slow", "fast", "e"}) {
+		t.Errorf("round 2 order %v, want the buffed character first", got)
+	}
+}
+
+func TestTurnOrderTies(t *testing.T) {
+	firsts := map[string]int{}
+	for seed := int64(0); seed < 4; seed++ {
+		var orders [][]string
+		for range 2 {
+			a, b := fighter("a", true, 7), fighter("b", false, 7)
+			e := NewEngine([]*Character{a}, []*Character{b}, seed)
+			var order []string
+			for round := range 40 {
+				e.TotalRounds = round
+				e.planRound()
+				order = append(order, turnIDs(e)[0])
+			}
+			orders = append(orders, order)
+		}
+		if !slices.Equal(orders[0], orders[1]) {
+			t.Errorf("seed %d: tie order differs between runs", seed)
+		}
+		for _, id := range orders[0] {
+			firsts[id]++
+		}
+	}
+	// reshuffled every round, so neither side always wins the tie
+	if firsts["a"] < 40 || firsts["b"] < 40 {
+		t.Errorf("tie won %d times by a and %d by b over 160 rounds", firsts["a"], firsts["b"])
+	}
+}
+
+func TestATBTurnsFollowSpeed(t *testing.T) {
+	tests := []struct {
+		name       string
+		fast, slow float64
+	}{
+		{"twice as fast", 10, 5},
+		{"7 to 5", 7, 5},
+		{"equal", 6, 6},
+		{"no speed", 3, 0},
+	}
+	const rounds = 60
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine([]*Character{fighter("fast", true, tt.fast)}, []*Character{fighter("slow", false, tt.slow)}, 1)
+			e.UseATB()
+			turns := map[string]int{}
+			for round := range rounds {
+				if round > 0 {
+					e.TotalRounds = round
+					e.planRound()
+				}
+				for _, id := range turnIDs(e) {
+					turns[id]++
+				}
+			}
+			// no speed still creeps along at 1
+			fast, slow := tt.fast, max(tt.slow, 1)
+			if turns["slow"] != rounds {
+				t.Errorf("slowest acted %d times in %d rounds, want once a round", turns["slow"], rounds)
+			}
+			if want := rounds * fast / slow; math.Abs(float64(turns["fast"])-want) > 1 {
+				t.Errorf("fastest acted %d times in %d rounds, want about %g", turns["fast"], rounds, want)
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package sim
 
+import "slices"
+
 // Character holds all mutable state for a combatant.
 type Character struct {
 	ID        string
@@ -15,11 +17,19 @@ type Character struct {
 	Speed     float64
 	Evasion   float64
 	Shields   int
-	Elements  []Element // e.g. Fire, Water, Earth, etc.
+	Elements  []Element // base elements from the template, e.g. Fire, Water; see CurrentElements
 	Abilities []*Ability
 
+	CritChance float64 // bonus on top of the mana-driven chance; see CritChance
+
 	ActiveBuffs   []BuffInstance
 	ActiveDebuffs []DebuffInstance
+
+	// Per-battle ability bookkeeping; see Ready.
+	Turns    int            // own turns started so far, lost ones included
+	ReadyAt  map[string]int // ability ID → first value of Turns it is off cooldown
+	Used     map[string]int // ability ID → times used
+	Charging *Charge        // the charge ability being wound up, if any
 }
 
 // BuffInstance represents one application of a buff.
@@ -34,6 +44,8 @@ type BuffInstance struct {
 // DebuffInstance represents one application of a debuff.
 type DebuffInstance struct {
 	AppliedBy      string
+	AppliedByAlly  bool    // side AppliedBy fights on
+	AbilityID      string  // ability that applied it, for DoT attribution
 	ModifierPct    float64 // –25 for –25% defense-down, or 0 if it's a pure DoT
 	DamagePercent  float64 // >0 if it deals DoT each round
 	ElementToApply Element // element to apply as debuff, if any
@@ -42,3 +54,17 @@ type DebuffInstance struct {
 	Stat           string  // e.g. "Defense" or empty if pure DoT
 	Element        Element // for elemental modifiers, if needed
 }
+
+// CurrentElements returns c's base Elements followed by every element its
+// active "element" debuffs lend it, without repeats. Weakness and resistance
+// math uses this set; Elements itself never changes during a battle.
+func (c *Character) CurrentElements() []Element {
+	// capped so appending never writes into the base slice
+	out := c.Elements[:len(c.Elements):len(c.Elements)]
+	for _, db := range c.ActiveDebuffs {
+		if db.Stat == "element" && db.ElementToApply != "" && !slices.Contains(out, db.ElementToApply) {
+			out = append(out, db.ElementToApply)
+		}
+	}
+	return out
+}
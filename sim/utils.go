@@ -2,7 +2,10 @@ package sim
 
 import "math"
 
+// MAX_MANA_CRIT_CHANCE is the crit chance a character at full mana gets
+// from its mana alone; CRIT_MULTIPLIER scales the damage of a crit.
 const MAX_MANA_CRIT_CHANCE = 0.34
+const CRIT_MULTIPLIER = 1.5
 
 // EffectiveEvasion returns the miss‐chance [0,0.75] for a character,
 // combining base Evasion plus any “evasion” buffs.
@@ -21,6 +24,19 @@ func EffectiveEvasion(c *Character) float64 {
 	return ev
 }
 
+// CritChance returns the chance [0,1] that c's next damaging hit is a crit:
+// its share of MaxMana times MAX_MANA_CRIT_CHANCE, plus its template bonus.
+// The engine takes it before c pays for the ability, which is also what a
+// policy sees when it chooses; a charge ability keeps the chance c had when
+// it started charging.
+func CritChance(c *Character) float64 {
+	fromMana := 0.0
+	if c.MaxMana > 0 {
+		fromMana = math.Min(c.Mana/c.MaxMana, 1) * MAX_MANA_CRIT_CHANCE
+	}
+	return clamp(fromMana+c.CritChance, 0, 1)
+}
+
 // GetEffectiveModifierForAttack returns the multiplicative damage modifier
 // based on strength‐type buffs/debuffs on source, or defense‐type on target.
 // stat should be either "strength" or "defense".
@@ -0,0 +1,81 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCritChance(t *testing.T) {
+	tests := []struct {
+		name          string
+		mana, maxMana float64
+		bonus         float64
+		want          float64
+	}{
+		{"empty", 0, 10, 0, 0},
+		{"half mana", 5, 10, 0, 0.17},
+		{"full mana", 10, 10, 0, 0.34},
+		{"full mana with bonus", 10, 10, 0.1, 0.44},
+		{"over full", 20, 10, 0, 0.34},
+		{"no mana pool", 0, 0, 0.05, 0.05},
+		{"clamped high", 10, 10, 0.9, 1},
+		{"clamped low", 0, 10, -0.2, 0},
+	}
+	for _, tt := range tests {
+		c := &Character{Mana: tt.mana, MaxMana: tt.maxMana, CritChance: tt.bonus}
+		if got := CritChance(c); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s: CritChance = %g, want %g", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestCritChanceBeforePaying pins the crit chance the engine rolls against to
+// the mana the attacker had when it chose, which is what UtilityDecision
+// scores: full mana plus a 0.66 bonus crits every time even though paying 4
+// would drop the chance to 0.864, and an empty attacker with no bonus never
+// crits even though the attack refills 4 mana first.
+func TestCritChanceBeforePaying(t *testing.T) {
+	tests := []struct {
+		name     string
+		mana     float64
+		bonus    float64
+		cost     float64
+		charge   int
+		wantCrit bool
+	}{
+		{"paid", 10, 0.66, 4, 0, true},
+		{"refund", 0, 0, -4, 0, false},
+		{"charged", 10, 0.66, 4, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strike := &Ability{ID: "strike", Type: "attack", TargetType: "single", TargetSelectType: "enemy",
+				Power: 30, ManaCost: tt.cost, ChargeTurns: tt.charge}
+			for seed := int64(0); seed < 40; seed++ {
+				attacker, target := fighter("a", true, 10), fighter("e", false, 5)
+				attacker.Mana, attacker.CritChance = tt.mana, tt.bonus
+				target.Health, target.MaxHealth = 1000, 1000
+				e := NewEngine([]*Character{attacker}, []*Character{target}, seed)
+				var hits, crits int
+				e.Events = EventSinkFunc(func(ev Event) {
+					if ev.Type == EventDamage {
+						hits++
+						if ev.Crit {
+							crits++
+						}
+					}
+				})
+				policy := scripted(map[string][]move{"a": {{strike, "e"}}})
+				for hits == 0 && e.TotalRounds < 3 {
+					e.Step(policy)
+				}
+				if hits != 1 {
+					t.Fatalf("seed %d: %d hits, want 1", seed, hits)
+				}
+				if (crits == 1) != tt.wantCrit {
+					t.Fatalf("seed %d: crit %v, want %v", seed, crits == 1, tt.wantCrit)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package sim
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ValidationError is one problem found in a data pack. Location is a dotted
+// path such as "abilities.soak.debuff.element_to_apply".
+type ValidationError struct {
+	Location string
+	Message  string
+}
+
+func (v ValidationError) Error() string {
+	return v.Location + ": " + v.Message
+}
+
+// ValidationErrors is every problem found in a data pack, in a stable order.
+type ValidationErrors []ValidationError
+
+func (v ValidationErrors) Error() string {
+	lines := make([]string, len(v))
+	for i, e := range v {
+		lines[i] = e.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Recognised string values for ability data.
+var (
+	AbilityTypes       = []string{"attack", "heal", "buff", "debuff"}
+	TargetTypes        = []string{"single", "all", "self"}
+	TargetSelectTypes  = []string{"ally", "enemy", "any"}
+	BuffTypes          = []string{"defense", "strength", "evasion", "speed", "shield"}
+	StatDebuffTypes    = []string{"defense", "strength", "speed"}
+	DoTDebuffTypes     = []string{"poison", "burn"}
+	ElementDebuffTypes = []string{"element"}
+	ControlDebuffTypes = []string{DebuffStun, DebuffSleep, DebuffSilence, DebuffTaunt}
+)
+
+// Validate checks templates, abilities and the element chart together and
+// returns every problem found, or nil if the pack is usable.
+func (p *DataPack) Validate() ValidationErrors {
+	var errs ValidationErrors
+	add := func(loc, format string, args ...any) {
+		errs = append(errs, ValidationError{Location: loc, Message: fmt.Sprintf(format, args...)})
+	}
+	knownElement := func(e Element) bool {
+		_, ok := p.Elements[e]
+		return ok
+	}
+
+	// element chart
+	for _, el := range slices.Sorted(maps.Keys(p.Elements)) {
+		info := p.Elements[el]
+		loc := "elements." + string(el)
+		for i, s := range info.Strengths {
+			if !knownElement(s) {
+				add(fmt.Sprintf("%s.strengths[%d]", loc, i), "unknown element %q", s)
+			}
+			if slices.Contains(info.Weaknesses, s) {
+				add(fmt.Sprintf("%s.strengths[%d]", loc, i), "%q is listed as both a strength and a weakness", s)
+			}
+			if slices.Index(info.Strengths, s) != i {
+				add(fmt.Sprintf("%s.strengths[%d]", loc, i), "duplicate element %q", s)
+			}
+		}
+		for i, w := range info.Weaknesses {
+			if !knownElement(w) {
+				add(fmt.Sprintf("%s.weaknesses[%d]", loc, i), "unknown element %q", w)
+			}
+			if slices.Index(info.Weaknesses, w) != i {
+				add(fmt.Sprintf("%s.weaknesses[%d]", loc, i), "duplicate element %q", w)
+			}
+		}
+	}
+
+	// abilities
+	for _, key := range slices.Sorted(maps.Keys(p.Abilities)) {
+		ab := p.Abilities[key]
+		loc := "abilities." + key
+		if ab == nil {
+			add(loc, "ability is empty")
+			continue
+		}
+		if ab.ID != key {
+			add(loc+".id", "id %q does not match its key", ab.ID)
+		}
+		if !slices.Contains(AbilityTypes, ab.Type) {
+			add(loc+".type", "unknown type %q, want one of %s", ab.Type, strings.Join(AbilityTypes, ", "))
+		}
+		if !slices.Contains(TargetTypes, ab.TargetType) {
+			add(loc+".target_type", "unknown target type %q, want one of %s", ab.TargetType, strings.Join(TargetTypes, ", "))
+		}
+		if !slices.Contains(TargetSelectTypes, ab.TargetSelectType) {
+			add(loc+".target_select_type", "unknown target select type %q, want one of %s", ab.TargetSelectType, strings.Join(TargetSelectTypes, ", "))
+		}
+		if !knownElement(ab.Element) {
+			add(loc+".element", "element %q is not in the element chart", ab.Element)
+		}
+		if ab.Power < 0 {
+			add(loc+".power", "power %g is negative", ab.Power)
+		}
+		for _, f := range []struct {
+			name string
+			v    int
+		}{
+			{"cooldown", ab.Cooldown},
+			{"initial_cooldown", ab.InitialCooldown},
+			{"uses", ab.Uses},
+			{"charge_turns", ab.ChargeTurns},
+		} {
+			if f.v < 0 {
+				add(loc+"."+f.name, "%d is negative", f.v)
+			}
+		}
+		if ab.Type == "heal" && ab.TargetSelectType == "enemy" {
+			add(loc+".target_select_type", "heal ability targets enemies")
+		}
+
+		switch {
+		case ab.Type == "buff" && ab.Buff == nil:
+			add(loc+".buff", "buff ability has no buff")
+		case ab.Type == "debuff" && ab.Debuff == nil:
+			add(loc+".debuff", "debuff ability has no debuff")
+		}
+
+		if b := ab.Buff; b != nil {
+			bloc := loc + ".buff"
+			if !slices.Contains(BuffTypes, b.Type) {
+				add(bloc+".type", "unknown buff type %q, want one of %s", b.Type, strings.Join(BuffTypes, ", "))
+			}
+			if b.Rounds <= 0 && b.Type != "shield" {
+				add(bloc+".rounds", "buff lasts %d rounds", b.Rounds)
+			}
+			if b.Type == "shield" && b.ModifierPercent < 1 {
+				add(bloc+".modifier_percent", "shield grants %g shields, want at least 1", b.ModifierPercent)
+			}
+		}
+
+		if d := ab.Debuff; d != nil {
+			dloc := loc + ".debuff"
+			known := slices.Concat(StatDebuffTypes, DoTDebuffTypes, ElementDebuffTypes, ControlDebuffTypes)
+			if !slices.Contains(known, d.Type) {
+				add(dloc+".type", "unknown debuff type %q, want one of %s", d.Type, strings.Join(known, ", "))
+			}
+			if d.Rounds <= 0 {
+				add(dloc+".rounds", "debuff lasts %d rounds", d.Rounds)
+			}
+			if d.ApplicationChance <= 0 || d.ApplicationChance > 100 {
+				add(dloc+".application_chance", "chance %g is outside (0, 100]", d.ApplicationChance)
+			}
+			if d.Element != "" && !knownElement(d.Element) {
+				add(dloc+".element", "element %q is not in the element chart", d.Element)
+			}
+			switch {
+			case slices.Contains(DoTDebuffTypes, d.Type) && d.DamagePercent <= 0:
+				add(dloc+".damage_percent", "%s debuff deals no damage", d.Type)
+			case slices.Contains(StatDebuffTypes, d.Type) && d.ModifierPercent == 0:
+				add(dloc+".modifier_percent", "%s debuff has no modifier", d.Type)
+			case slices.Contains(ElementDebuffTypes, d.Type) && !knownElement(d.ElementToApply):
+				add(dloc+".element_to_apply", "element %q is not in the element chart", d.ElementToApply)
+			case d.Type == DebuffTaunt && ab.TargetSelectType != "enemy":
+				add(loc+".target_select_type", "taunt must target enemies, not %q", ab.TargetSelectType)
+			}
+		}
+	}
+
+	// characters
+	for _, key := range slices.Sorted(maps.Keys(p.Characters)) {
+		t := p.Characters[key]
+		loc := "characters." + key
+		if len(t.Elements) == 0 {
+			add(loc+".elements", "character has no element")
+		}
+		for i, el := range t.Elements {
+			if !knownElement(el) {
+				add(fmt.Sprintf("%s.elements[%d]", loc, i), "element %q is not in the element chart", el)
+			}
+		}
+		if t.BaseHealth <= 0 {
+			add(loc+".base_health", "base health %g must be positive", t.BaseHealth)
+		}
+		if t.BaseMana <= 0 {
+			add(loc+".base_mana", "base mana %g must be positive", t.BaseMana)
+		}
+		for _, f := range []struct {
+			name string
+			v    float64
+		}{
+			{"base_strength", t.BaseStrength},
+			{"base_defense", t.BaseDefense},
+			{"base_spirit", t.BaseSpirit},
+			{"base_speed", t.BaseSpeed},
+			{"hp_growth", t.HPGrowth},
+			{"strength_growth", t.StrengthGrowth},
+			{"defense_growth", t.DefenseGrowth},
+			{"spirit_growth", t.SpiritGrowth},
+			{"speed_growth", t.SpeedGrowth},
+		} {
+			if f.v < 0 {
+				add(loc+"."+f.name, "%g is negative", f.v)
+			}
+		}
+		if t.Evasion < 0 || t.Evasion > 1 {
+			add(loc+".evasion", "evasion %g is outside [0, 1]", t.Evasion)
+		}
+		if t.CritChance < 0 || t.CritChance > 1 {
+			add(loc+".crit_chance", "crit chance %g is outside [0, 1]", t.CritChance)
+		}
+		if len(t.AbilityTemplates) == 0 {
+			add(loc+".abilities", "character has no abilities")
+		}
+		seen := map[string]bool{}
+		for i, at := range t.AbilityTemplates {
+			aloc := fmt.Sprintf("%s.abilities[%d]", loc, i)
+			if _, ok := p.Abilities[at.Key]; !ok {
+				add(aloc+".key", "unknown ability %q", at.Key)
+			}
+			if seen[at.Key] {
+				add(aloc+".key", "duplicate ability %q", at.Key)
+			}
+			seen[at.Key] = true
+			if at.MinLv < 0 {
+				add(aloc+".min_lv", "min level %d is negative", at.MinLv)
+			}
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,180 @@
+package sim
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateBuiltin(t *testing.T) {
+	if errs := DefaultPack().Validate(); errs != nil {
+		t.Fatalf("built-in data has problems:\n%v", errs)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		edit     func(p *DataPack)
+		location string
+	}{
+		// element chart
+		{"unknown strength", func(p *DataPack) {
+			info := p.Elements[Fire]
+			info.Strengths = append(info.Strengths, "plasma")
+			p.Elements[Fire] = info
+		}, "elements.fire.strengths[2]"},
+		{"strength and weakness", func(p *DataPack) {
+			info := p.Elements[Fire]
+			info.Weaknesses = append(info.Weaknesses, info.Strengths[0])
+			p.Elements[Fire] = info
+		}, "elements.fire.strengths[0]"},
+		{"duplicate weakness", func(p *DataPack) {
+			info := p.Elements[Fire]
+			info.Weaknesses = append(info.Weaknesses, info.Weaknesses[0])
+			p.Elements[Fire] = info
+		}, "elements.fire.weaknesses[2]"},
+
+		// abilities
+		{"empty ability", func(p *DataPack) { p.Abilities["bash"] = nil }, "abilities.bash"},
+		{"id mismatch", func(p *DataPack) { p.Abilities["bash"].ID = "smash" }, "abilities.bash.id"},
+		{"ability type", func(p *DataPack) { p.Abilities["bash"].Type = "dance" }, "abilities.bash.type"},
+		{"target type", func(p *DataPack) { p.Abilities["bash"].TargetType = "row" }, "abilities.bash.target_type"},
+		{"target select type", func(p *DataPack) { p.Abilities["bash"].TargetSelectType = "self" }, "abilities.bash.target_select_type"},
+		{"ability element", func(p *DataPack) { p.Abilities["bash"].Element = "plasma" }, "abilities.bash.element"},
+		{"negative power", func(p *DataPack) { p.Abilities["bash"].Power = -1 }, "abilities.bash.power"},
+		{"negative cooldown", func(p *DataPack) { p.Abilities["bash"].Cooldown = -1 }, "abilities.bash.cooldown"},
+		{"negative uses", func(p *DataPack) { p.Abilities["bash"].Uses = -2 }, "abilities.bash.uses"},
+		{"heal on enemies", func(p *DataPack) {
+			p.Abilities["nature-blessing"].TargetSelectType = "enemy"
+		}, "abilities.nature-blessing.target_select_type"},
+		{"buff ability without buff", func(p *DataPack) { p.Abilities["barkskin"].Buff = nil }, "abilities.barkskin.buff"},
+		{"debuff ability without debuff", func(p *DataPack) { p.Abilities["scorch"].Debuff = nil }, "abilities.scorch.debuff"},
+		{"buff type", func(p *DataPack) { p.Abilities["barkskin"].Buff.Type = "luck" }, "abilities.barkskin.buff.type"},
+		{"buff rounds", func(p *DataPack) { p.Abilities["barkskin"].Buff.Rounds = 0 }, "abilities.barkskin.buff.rounds"},
+		{"empty shield", func(p *DataPack) { p.Abilities["astral-veil"].Buff.ModifierPercent = 0 }, "abilities.astral-veil.buff.modifier_percent"},
+		{"debuff type", func(p *DataPack) { p.Abilities["scorch"].Debuff.Type = "frostbite" }, "abilities.scorch.debuff.type"},
+		{"debuff rounds", func(p *DataPack) { p.Abilities["scorch"].Debuff.Rounds = 0 }, "abilities.scorch.debuff.rounds"},
+		{"debuff chance", func(p *DataPack) { p.Abilities["scorch"].Debuff.ApplicationChance = 101 }, "abilities.scorch.debuff.application_chance"},
+		{"debuff element", func(p *DataPack) { p.Abilities["scorch"].Debuff.Element = "plasma" }, "abilities.scorch.debuff.element"},
+		{"DoT without damage", func(p *DataPack) { p.Abilities["scorch"].Debuff.DamagePercent = 0 }, "abilities.scorch.debuff.damage_percent"},
+		{"stat debuff without modifier", func(p *DataPack) { p.Abilities["oak-shackle"].Debuff.ModifierPercent = 0 }, "abilities.oak-shackle.debuff.modifier_percent"},
+		{"element to apply", func(p *DataPack) { p.Abilities["soak"].Debuff.ElementToApply = "plasma" }, "abilities.soak.debuff.element_to_apply"},
+		{"taunt on allies", func(p *DataPack) {
+			d := p.Abilities["soak"].Debuff
+			d.Type = DebuffTaunt
+			d.ElementToApply = ""
+		}, "abilities.soak.target_select_type"},
+
+		// characters
+		{"no element", func(p *DataPack) {
+			c := p.Characters["mycera"]
+			c.Elements = nil
+			p.Characters["mycera"] = c
+		}, "characters.mycera.elements"},
+		{"character element", func(p *DataPack) {
+			c := p.Characters["mycera"]
+			c.Elements = []Element{"plasma"}
+			p.Characters["mycera"] = c
+		}, "characters.mycera.elements[0]"},
+		{"base health", func(p *DataPack) {
+			c := p.Characters["mycera"]
+			c.BaseHealth = 0
+			p.Characters["mycera"] = c
+		}, "characters.mycera.base_health"},
+		{"base mana", func(p *DataPack) {
+			c := p.Characters["mycera"]
+			c.BaseMana = -1
+			p.Characters["mycera"] = c
+		}, "characters.mycera.base_mana"},
+		{"negative growth", func(p *DataPack) {
+			c := p.Characters["mycera"]
+			c.SpeedGrowth = -1
+			p.Characters["mycera"] = c
+		}, "characters.mycera.speed_growth"},
+		{"evasion", func(p *DataPack) {
+			c := p.Characters["mycera"]
+			c.Evasion = 1.5
+			p.Characters["mycera"] = c
+		}, "characters.mycera.evasion"},
+		{"crit chance", func(p *DataPack) {
+			c := p.Characters["mycera"]
+			c.CritChance = -0.1
+			p.Characters["mycera"] = c
+		}, "characters.mycera.crit_chance"},
+		{"no abilities", func(p *DataPack) {
+			c := p.Characters["mycera"]
+			c.AbilityTemplates = nil
+			p.Characters["mycera"] = c
+		}, "characters.mycera.abilities"},
+		{"unknown ability", func(p *DataPack) {
+			c := p.Characters["mycera"]
+			c.AbilityTemplates = append(c.AbilityTemplates, CharacterAbilityTemplate{Key: "no-such-ability"})
+			p.Characters["mycera"] = c
+		}, "characters.mycera.abilities[3].key"},
+		{"duplicate ability", func(p *DataPack) {
+			c := p.Characters["mycera"]
+			c.AbilityTemplates = append(c.AbilityTemplates, c.AbilityTemplates[0])
+			p.Characters["mycera"] = c
+		}, "characters.mycera.abilities[3].key"},
+		{"negative min level", func(p *DataPack) {
+			c := p.Characters["mycera"]
+			c.AbilityTemplates = append(c.AbilityTemplates[:0:0], c.AbilityTemplates...)
+			c.AbilityTemplates[0].MinLv = -1
+			p.Characters["mycera"] = c
+		}, "characters.mycera.abilities[0].min_lv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := DefaultPack()
+			tt.edit(p)
+			errs := p.Validate()
+			for _, e := range errs {
+				if e.Location == tt.location {
+					return
+				}
+			}
+			t.Errorf("no problem reported at %s; got:\n%v", tt.location, errs)
+		})
+	}
+}
+
+func TestMakeTeamErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+		edit func(p *DataPack)
+		want string // substring of the error, or "" for success
+	}{
+		{"built-in", []string{"mycera", "pondril"}, nil, ""},
+		{"unknown character", []string{"mycera", "nobody"}, nil, `unknown character key "nobody"`},
+		{"unknown ability", []string{"mycera"}, func(p *DataPack) {
+			c := p.Characters["mycera"]
+			c.AbilityTemplates = append(c.AbilityTemplates, CharacterAbilityTemplate{Key: "no-such-ability", MinLv: 99})
+			p.Characters["mycera"] = c
+		}, `unknown ability "no-such-ability"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := DefaultPack()
+			if tt.edit != nil {
+				tt.edit(p)
+			}
+			withPack(t, p)
+			_, err := MakeTeam(tt.keys, 5, true)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Fatalf("error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// withPack installs p for the rest of the test and puts the built-in data
+// back afterwards.
+func withPack(t *testing.T, p *DataPack) {
+	t.Helper()
+	p.Install()
+	t.Cleanup(func() { DefaultPack().Install() })
+}